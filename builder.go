@@ -0,0 +1,89 @@
+package zerrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BuilderError is the code of the errors returned by Builder.Build.
+type BuilderError string
+
+const (
+	BuilderErrEmptyCode    BuilderError = "builder_empty_code"
+	BuilderErrEmptyKey     BuilderError = "builder_empty_key"
+	BuilderErrDuplicateKey BuilderError = "builder_duplicate_key"
+)
+
+// Builder assembles an Error step by step and validates it on Build.
+// Use New for the simple cases.
+type Builder[T ~string] struct {
+	code     T
+	tags     []string
+	data     map[string]any
+	wrapped  []error
+	message  string
+	problems []error
+}
+
+// NewBuilder creates an empty Builder.
+func NewBuilder[T ~string]() *Builder[T] {
+	return &Builder[T]{
+		data: map[string]any{},
+	}
+}
+
+// Code sets the code of the Error.
+func (b *Builder[T]) Code(code T) *Builder[T] {
+	b.code = code
+	return b
+}
+
+// Tag adds tags to the Error.
+func (b *Builder[T]) Tag(tags ...string) *Builder[T] {
+	b.tags = append(b.tags, tags...)
+	return b
+}
+
+// Data attaches a value to the Error. Each key may only be set once.
+func (b *Builder[T]) Data(key string, value any) *Builder[T] {
+	switch _, dup := b.data[key]; {
+	case key == "":
+		b.problems = append(b.problems, newWithSkip(BuilderErrEmptyKey, 1, WithoutStack()))
+	case dup:
+		b.problems = append(b.problems, newWithSkip(BuilderErrDuplicateKey, 1, WithoutStack()).With("key", key))
+	default:
+		b.data[key] = value
+	}
+	return b
+}
+
+// Wrap adds an error wrapped by the Error. Several errors are wrapped as by WithErrors.
+func (b *Builder[T]) Wrap(err error) *Builder[T] {
+	if err != nil {
+		b.wrapped = append(b.wrapped, err)
+	}
+	return b
+}
+
+// Message sets the formatted message of the Error.
+func (b *Builder[T]) Message(format string, a ...any) *Builder[T] {
+	b.message = fmt.Sprintf(format, a...)
+	return b
+}
+
+// Build validates the configuration and returns the Error, with its stack
+// captured at the call site. The returned error joins every problem found,
+// each an *Error[BuilderError].
+func (b *Builder[T]) Build() (*Error[T], error) {
+	problems := b.problems
+	if b.code == "" {
+		problems = append(problems, newWithSkip(BuilderErrEmptyCode, 1, WithoutStack()))
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+
+	e := newWithSkip(b.code, 1, WithInitialTags(b.tags...), WithInitialData(b.data))
+	e.message = b.message
+	return e.WithErrors(b.wrapped...).created(), nil
+}
@@ -0,0 +1,270 @@
+package zerrors
+
+import (
+	"iter"
+	"slices"
+)
+
+// maxChainDepth bounds the number of links visited when walking a chain,
+// so a cyclic chain cannot hang the walk.
+const maxChainDepth = 10_000
+
+// chainNode is implemented by every Error, regardless of its code type, so that
+// the package can read any Error in a chain without knowing its code type.
+type chainNode interface {
+	CodeString() string
+	GetTags() []string
+	Get(key string) (any, bool)
+	Severity() Severity
+	exportedData() map[string]any
+	messageParts() (code, message string, wrapped error)
+	verboseHead() string
+	isNil() bool
+}
+
+// Codes returns the codes of every Error in the chain of err, ordered from
+// the outermost to the innermost as visited by Iter, without duplicates.
+func Codes(err error) []string {
+	var codes []string
+	seen := map[string]struct{}{}
+
+	for e := range Iter(err) {
+		c, ok := e.(chainNode)
+		if !ok {
+			continue
+		}
+		code := c.CodeString()
+		if _, dup := seen[code]; dup {
+			continue
+		}
+		seen[code] = struct{}{}
+		codes = append(codes, code)
+	}
+
+	return codes
+}
+
+// HasCodeString reports whether any Error in the chain of err has the given code,
+// compared as a plain string so that the code type of each Error does not matter.
+func HasCodeString(err error, code string) bool {
+	for e := range Iter(err) {
+		if c, ok := e.(chainNode); ok && c.CodeString() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// AllTags returns the union of the tags of every Error in the chain of err,
+// sorted and without duplicates.
+//
+// Unlike GetTags, which reflects the tags propagated when wrapping, the chain
+// is walked at call time, so tags added to inner errors after wrapping are included.
+func AllTags(err error) []string {
+	var tags []string
+
+	for e := range Iter(err) {
+		if t, ok := e.(chainNode); ok {
+			tags = append(tags, t.GetTags()...)
+		}
+	}
+
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// AnyTag reports whether any Error in the chain of err has at least one of the given tags.
+// Unlike HasAnyTags, which inspects a single Error, the whole chain is walked.
+func AnyTag(err error, tags ...string) bool {
+	all := AllTags(err)
+	for _, tag := range tags {
+		if _, found := slices.BinarySearch(all, tag); found {
+			return true
+		}
+	}
+	return false
+}
+
+// EveryTag reports whether each of the given tags is held by some Error in the chain of err.
+// Unlike HasAllTags, which inspects a single Error, the whole chain is walked.
+func EveryTag(err error, tags ...string) bool {
+	all := AllTags(err)
+	for _, tag := range tags {
+		if _, found := slices.BinarySearch(all, tag); !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FlattenData merges the data of every Error in the chain of err into a single map.
+// On conflicting keys the outermost error wins. Sensitive values are redacted,
+// since the result is meant to leave the process, e.g. as RPC error details.
+func FlattenData(err error) map[string]any {
+	data := map[string]any{}
+	for e := range Iter(err) {
+		d, ok := e.(chainNode)
+		if !ok {
+			continue
+		}
+		for k, v := range d.exportedData() {
+			if _, exists := data[k]; !exists {
+				data[k] = v
+			}
+		}
+	}
+	return data
+}
+
+// NodeSnapshot is the stack-free view of one error of a chain, see ChainSnapshot.
+type NodeSnapshot struct {
+	Code    string
+	Data    map[string]any
+	Tags    []string
+	Message string
+}
+
+// ChainSnapshot returns one node per error in the chain of err, in the order of Iter,
+// to compare whole chains against golden expectations in tests.
+// Errors of this package fill Code, Data (redacted, nil when empty), Tags (sorted,
+// nil when empty) and their own Message, other errors only fill Message with their
+// message. The errors grouping the branches of WithErrors are skipped.
+func ChainSnapshot(err error) []NodeSnapshot {
+	var nodes []NodeSnapshot
+	for e := range Iter(err) {
+		if _, ok := e.(*joinedError); ok {
+			continue
+		}
+		n, ok := e.(chainNode)
+		if !ok {
+			nodes = append(nodes, NodeSnapshot{Message: e.Error()})
+			continue
+		}
+
+		code, message, _ := n.messageParts()
+		node := NodeSnapshot{Code: code, Message: message}
+		if data := n.exportedData(); len(data) > 0 {
+			node.Data = data
+		}
+		if tags := n.GetTags(); len(tags) > 0 {
+			slices.Sort(tags)
+			node.Tags = tags
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Iter returns an iterator over err and every error in its chain, from the
+// outermost to the leaves, including errors not created by this package.
+// Errors implementing Unwrap() []error are traversed depth-first, in order.
+// Nil *Error values, such as the result of Wrap(nil, code), are skipped.
+func Iter(err error) iter.Seq[error] {
+	err = orNil(err)
+	return func(yield func(error) bool) {
+		if err == nil {
+			return
+		}
+		pending := []error{err}
+		for visited := 0; len(pending) > 0 && visited < maxChainDepth; visited++ {
+			current := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			if !yield(current) {
+				return
+			}
+			pending = append(pending, reversed(unwrapAll(current))...)
+		}
+	}
+}
+
+// Leaves returns every error in the chain of err that wraps no other error,
+// such as the root causes of each branch of a joined error, from left to right.
+func Leaves(err error) []error {
+	var leaves []error
+	for e := range Iter(err) {
+		if len(unwrapAll(e)) == 0 {
+			leaves = append(leaves, e)
+		}
+	}
+	return leaves
+}
+
+// Cause returns the innermost error of a linear chain, following Unwrap() error.
+// It stops at an error wrapping several errors, since there is no single cause
+// past it; use Leaves to get all of them. It returns nil if err is nil.
+func Cause(err error) error {
+	for depth := 0; depth < maxChainDepth; depth++ {
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		next := u.Unwrap()
+		if next == nil {
+			break
+		}
+		err = next
+	}
+	return err
+}
+
+// unwrapAll returns the errors directly wrapped by err.
+func unwrapAll(err error) []error {
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := orNil(x.Unwrap()); next != nil {
+			return []error{next}
+		}
+	case interface{ Unwrap() []error }:
+		return slices.DeleteFunc(slices.Clone(x.Unwrap()), func(e error) bool { return orNil(e) == nil })
+	}
+	return nil
+}
+
+func reversed(errs []error) []error {
+	slices.Reverse(errs)
+	return errs
+}
+
+// chainLen returns the number of errors in the chain of err, err included.
+func chainLen(err error) int {
+	n := 0
+	for range Iter(err) {
+		n++
+	}
+	return n
+}
+
+// reaches reports whether target is err itself or is reachable by unwrapping err.
+// It runs on every wrap, so it follows Unwrap() error without allocating and
+// only falls back to Iter at the first Unwrap() []error.
+func reaches(err, target error) bool {
+	current := orNil(err)
+	for range maxChainDepth {
+		if current == nil {
+			return false
+		}
+		if current == target {
+			return true
+		}
+		switch x := current.(type) {
+		case interface{ Unwrap() error }:
+			current = orNil(x.Unwrap())
+		case interface{ Unwrap() []error }:
+			return iterReaches(current, target)
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// iterReaches is the Iter walk behind reaches, kept out of it so that the
+// range-over-func state is only allocated for chains that branch.
+func iterReaches(err, target error) bool {
+	for current := range Iter(err) {
+		if current == target {
+			return true
+		}
+	}
+	return false
+}
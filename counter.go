@@ -0,0 +1,76 @@
+package zerrors
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CounterEntry is the number of errors counted by a Counter for a code and set of tags.
+type CounterEntry struct {
+	Code  string
+	Tags  []string
+	Count int
+}
+
+// Counter counts errors by code and tags, to log a summary of the errors
+// of a batch instead of each of them. It is safe for concurrent use.
+type Counter[T ~string] struct {
+	mu      sync.Mutex
+	entries map[string]*CounterEntry
+}
+
+// NewCounter creates an empty Counter.
+func NewCounter[T ~string]() *Counter[T] {
+	return &Counter[T]{
+		entries: map[string]*CounterEntry{},
+	}
+}
+
+// Add counts err under the code and sorted tags of the first *Error[T] in its chain.
+// Errors without one are counted under the empty code. A nil err is ignored.
+func (c *Counter[T]) Add(err error) {
+	if err == nil {
+		return
+	}
+
+	var code string
+	var tags []string
+	if zerr, ok := AsError[T](err); ok {
+		code = zerr.CodeString()
+		if t := zerr.GetTags(); len(t) > 0 {
+			tags = t
+			slices.Sort(tags)
+		}
+	}
+	key := code + "\x00" + strings.Join(tags, "\x00")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		entry.Count++
+		return
+	}
+	c.entries[key] = &CounterEntry{Code: code, Tags: tags, Count: 1}
+}
+
+// Summary returns the counted entries, the most frequent first,
+// then ordered by code.
+func (c *Counter[T]) Summary() []CounterEntry {
+	c.mu.Lock()
+	summary := make([]CounterEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		summary = append(summary, CounterEntry{Code: entry.Code, Tags: slices.Clone(entry.Tags), Count: entry.Count})
+	}
+	c.mu.Unlock()
+
+	slices.SortFunc(summary, func(a, b CounterEntry) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.Code, b.Code),
+			slices.Compare(a.Tags, b.Tags),
+		)
+	})
+	return summary
+}
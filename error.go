@@ -1,107 +1,597 @@
 package zerrors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/emirpasic/gods/v2/sets/hashset"
 )
 
+// Error is a domain error identified by a code of type T.
+//
+// The read accessors Error, Code, CodeString, GetTags, HasAllTags, HasAnyTags,
+// Get, Has, Unwrap, Is, As and LogValue are safe to call on a nil *Error, returning zero
+// values, as a nil result of Wrap may still be inspected in error handling paths.
 type Error[T ~string] struct {
 	code       T
+	message    string
 	wrappedErr error
-	tags       *hashset.Set[string]
+	tags       *hashset.Set[string] // nil until the first tag is added
 	data       map[string]any
+	sensitive  map[string]struct{}
 	stack      *stack
+	render     renderConfig
+	// matchTags makes the error, used as an errors.Is target, require its tags.
+	matchTags       bool
+	recordWrapSites bool
+	wrapSites       []Frame
+	noContextTags   bool
+	noPropagateTags bool
+	timeout         bool
+	goroutineID     uint64
+	createdAt       time.Time
+	dataTruncated   bool
+	severity        Severity
+}
+
+// Tags added automatically when wrapping context errors, see WithoutContextTags.
+const (
+	TagCanceled = "canceled"
+	TagTimeout  = "timeout"
+)
+
+// redacted replaces the value of sensitive keys in log output.
+const redacted = "[REDACTED]"
+
+// New creates a new Error instance, configured by the given options.
+func New[T ~string](code T, opts ...Option) *Error[T] {
+	return newWithSkip(code, 1, opts...).created()
+}
+
+// Newf creates a new Error with the given code and a formatted message.
+// The message is kept apart from the wrapped error, so a later WithError
+// does not replace it.
+func Newf[T ~string](code T, format string, a ...any) *Error[T] {
+	e := newWithSkip(code, 1)
+	e.message = fmt.Sprintf(format, a...)
+	return e.created()
+}
+
+// NewWithStack creates a new Error with the stack given as program counters,
+// such as those returned by runtime.Callers, instead of capturing one.
+// Capturing them in a deferred recover makes the stack of a recovered panic
+// point at the panic site. The Error has no stack if pcs is empty or none of
+// them resolves to a function.
+func NewWithStack[T ~string](code T, pcs []uintptr) *Error[T] {
+	e := newWithSkip(code, 1, WithoutStack())
+	if slices.ContainsFunc(pcs, func(pc uintptr) bool { return runtime.FuncForPC(pc) != nil }) {
+		e.stack = &stack{pcs: slices.Clone(pcs)}
+	}
+	return e.created()
+}
+
+// Wrap creates a new Error with the given code wrapping err.
+// It returns nil if err is nil, including a nil *Error returned by another Wrap,
+// so wraps can be layered across functions.
+//
+// Note that the result is a typed pointer: returning it from a function whose
+// result type is error yields a non-nil interface even when the pointer is nil.
+func Wrap[T ~string](err error, code T) *Error[T] {
+	if orNil(err) == nil {
+		return nil
+	}
+	return newWithSkip(code, 1).WithError(err).created()
+}
+
+// Wrapf creates a new Error with the given code and a formatted message wrapping err.
+// It returns nil if err is nil.
+func Wrapf[T ~string](err error, code T, format string, a ...any) *Error[T] {
+	if orNil(err) == nil {
+		return nil
+	}
+	e := newWithSkip(code, 1)
+	e.message = fmt.Sprintf(format, a...)
+	return e.WithError(err).created()
 }
 
-// New creates a new Error instance.
-func New[T ~string](code T) *Error[T] {
-	return &Error[T]{
+// Coerce returns err unchanged if it is an *Error[T], and otherwise wraps it
+// in a new Error with defaultCode. It returns nil if err is nil.
+// Only err itself is checked, so an *Error[T] wrapped by another error is wrapped again.
+func Coerce[T ~string](err error, defaultCode T) *Error[T] {
+	if orNil(err) == nil {
+		return nil
+	}
+	if zerr, ok := err.(*Error[T]); ok {
+		return zerr
+	}
+	return newWithSkip(defaultCode, 1).WithError(err).created()
+}
+
+// Annotate creates a new Error wrapping err with the code of the first *Error[T]
+// in its chain, so data and tags can be layered on without reclassifying the error.
+// If the chain holds no *Error[T], fallback is used. It returns nil if err is nil.
+func Annotate[T ~string](err error, fallback T) *Error[T] {
+	if orNil(err) == nil {
+		return nil
+	}
+	code := fallback
+	if zerr, ok := AsError[T](err); ok {
+		code = zerr.code
+	}
+	return newWithSkip(code, 1).WithError(err).created()
+}
+
+// newWithSkip creates a new Error, capturing the stack after skipping 'skip' frames
+// above its caller. Public constructors pass 1 so the stack starts at their caller.
+func newWithSkip[T ~string](code T, skip int, opts ...Option) *Error[T] {
+	cfg := newConfig(opts)
+
+	e := &Error[T]{
 		code:       code,
 		wrappedErr: nil,
 		data:       map[string]any{},
-		tags:       hashset.New[string](),
-		stack:      captureStack(1),
+		render:     cfg.render,
+
+		recordWrapSites: cfg.recordWrapSites,
+		noContextTags:   cfg.noContextTags,
+	}
+	for _, k := range slices.Sorted(maps.Keys(cfg.data)) {
+		if e.admitData(k) {
+			e.data[k] = cfg.data[k]
+		}
+	}
+	e.addTags(cfg.tags...)
+	if !cfg.noStack {
+		e.stack = captureStack(skip+1, cfg.stackDepth)
 	}
+	if cfg.goroutineID {
+		e.goroutineID = currentGoroutineID()
+	}
+	if cfg.timestamp {
+		e.createdAt = time.Now()
+	}
+	return e
 }
 
 func (e *Error[T]) LogValue() slog.Value {
+	if e == nil {
+		return slog.GroupValue()
+	}
+	return e.chainLogValue(nil, 0)
+}
+
+// chainLogValue renders the error at the given depth of the chain,
+// as wrapped by an error whose stack is parent, if any.
+// Only the outermost error renders the message of the whole chain, wrapped
+// errors render their own, so the output stays linear in the chain length.
+func (e *Error[T]) chainLogValue(parent *stack, depth int) slog.Value {
+	if e == nil {
+		return slog.GroupValue()
+	}
+	keys := currentLogKeys()
+
+	message := string(e.code)
+	if depth == 0 {
+		message = e.Error()
+	} else if e.message != "" {
+		message += ": " + e.message
+	}
+
 	// Create base attributes
 	attrs := []slog.Attr{
-		slog.String("code", string(e.code)),
-		slog.String("error", e.Error()),
+		slog.String(keys.Code, string(e.code)),
+		slog.String(keys.Error, message),
 	}
 
 	// Add data group if there's any custom data
-	if len(e.data) > 0 {
+	if len(e.data) > 0 && inlineData.Load() {
+		data := e.exportedData()
+		for _, k := range slices.Sorted(maps.Keys(data)) {
+			attrs = append(attrs, slog.Any(k, data[k]))
+		}
+	} else if len(e.data) > 0 {
 		// Convert map entries directly to key-value pairs for slog.Group
 		//nolint:mnd // 2 is the pair nr
 		dataArgs := make([]any, 0, len(e.data)*2)
-		for k, v := range e.data {
+		for k, v := range e.exportedData() {
 			dataArgs = append(dataArgs, k, v)
 		}
-		attrs = append(attrs, slog.Group("data", dataArgs...))
+		attrs = append(attrs, slog.Group(keys.Data, dataArgs...))
 	}
 
-	if !e.tags.Empty() {
-		attrs = append(attrs, slog.Any("tags", e.GetTags()))
+	if e.dataTruncated {
+		attrs = append(attrs, slog.Bool(keys.DataTruncated, true))
+	}
+
+	if e.tags != nil && !e.tags.Empty() {
+		attrs = append(attrs, slog.Any(keys.Tags, e.GetTags()))
 	}
 
 	// Handle wrapped error
 	if e.wrappedErr != nil {
-		if logValuer, ok := e.wrappedErr.(slog.LogValuer); ok {
-			attrs = append(attrs, slog.Any("wrapped", logValuer.LogValue()))
-		} else {
-			attrs = append(attrs, slog.String("wrapped", e.wrappedErr.Error()))
+		attrs = append(attrs, slog.Any(keys.Wrapped, wrappedLogValue(e.wrappedErr, e.stack, depth+1)))
+	}
+
+	if len(e.wrapSites) > 0 {
+		sites := make([]string, 0, len(e.wrapSites))
+		for _, site := range e.wrapSites {
+			sites = append(sites, site.String())
 		}
+		attrs = append(attrs, slog.Any(keys.WrapSites, sites))
+	}
+
+	if e.goroutineID != 0 {
+		attrs = append(attrs, slog.Uint64(keys.Goroutine, e.goroutineID))
+	}
+
+	if !e.createdAt.IsZero() {
+		attrs = append(attrs, slog.Time(keys.CreatedAt, e.createdAt))
 	}
 
-	if e.stack != nil {
-		attrs = append(attrs, slog.String("stack", e.stack.String()))
+	if e.stack != nil && e.render.slogSource {
+		attrs = append(attrs, slog.Any(keys.Stack, e.stack.sources(e.render)))
+	} else if e.stack != nil {
+		if !collapseSharedFrames.Load() {
+			parent = nil
+		}
+		attrs = append(attrs, slog.String(keys.Stack, e.stack.render(parent, e.render)))
 	}
 
 	return slog.GroupValue(attrs...)
 }
 
 func (e *Error[T]) Tags(tags ...string) *Error[T] {
-	e.tags.Add(tags...)
+	e.addTags(tags...)
 	return e
 }
 
+// addTags adds tags to e, allocating the set on first use since most errors have no tags.
+func (e *Error[T]) addTags(tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+	if e.tags == nil {
+		e.tags = hashset.New(tags...)
+		return
+	}
+	e.tags.Add(tags...)
+}
+
+// HasTags reports whether the error has all the given tags.
+//
+// Deprecated: Use HasAllTags or HasAnyTags, which make the semantics explicit.
 func (e *Error[T]) HasTags(tags ...string) bool {
+	return e.HasAllTags(tags...)
+}
+
+// HasAllTags reports whether the error has every one of the given tags.
+func (e *Error[T]) HasAllTags(tags ...string) bool {
+	if e == nil {
+		return false
+	}
+	if e.tags == nil {
+		return len(tags) == 0
+	}
 	return e.tags.Contains(tags...)
 }
 
+// HasAnyTags reports whether the error has at least one of the given tags.
+func (e *Error[T]) HasAnyTags(tags ...string) bool {
+	if e == nil || e.tags == nil {
+		return false
+	}
+	for _, tag := range tags {
+		if e.tags.Contains(tag) {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Error[T]) GetTags() []string {
+	if e == nil {
+		return nil
+	}
+	if e.tags == nil {
+		return []string{}
+	}
 	return e.tags.Values()
 }
 
 // TODO: see comm [Structured Errors in Go](https://news.ycombinator.com/item?id=44148734)
 func (e *Error[T]) With(k string, v any) *Error[T] {
+	if !e.admitData(k) {
+		return e
+	}
 	e.data[k] = v
 	return e
 }
 
+// WithSensitive attaches a value that is retrievable with Get but is
+// rendered as "[REDACTED]" in log output. The key stays sensitive
+// if it is later overwritten with With.
+func (e *Error[T]) WithSensitive(k string, v any) *Error[T] {
+	if !e.admitData(k) {
+		return e
+	}
+	if e.sensitive == nil {
+		e.sensitive = map[string]struct{}{}
+	}
+	e.sensitive[k] = struct{}{}
+	e.data[k] = v
+	return e
+}
+
+// admitData reports whether a value may be stored under k, given the cap set
+// with SetMaxDataEntries. Overwriting an existing key is always allowed.
+func (e *Error[T]) admitData(k string) bool {
+	limit := int(maxDataEntries.Load())
+	if _, exists := e.data[k]; exists || limit == 0 || len(e.data) < limit {
+		return true
+	}
+	e.dataTruncated = true
+	return false
+}
+
 func (e *Error[T]) Get(key string) (any, bool) {
+	if e == nil {
+		return nil, false
+	}
 	val, ok := e.data[key]
 	return val, ok
 }
 
+// Has reports whether data is attached under key.
+func (e *Error[T]) Has(key string) bool {
+	if e == nil {
+		return false
+	}
+	_, ok := e.data[key]
+	return ok
+}
+
+// Delete removes the data attached under key, if any.
+func (e *Error[T]) Delete(key string) *Error[T] {
+	delete(e.data, key)
+	delete(e.sensitive, key)
+	return e
+}
+
+// GetData returns a copy of all the data attached to the error.
+func (e *Error[T]) GetData() map[string]any {
+	return maps.Clone(e.data)
+}
+
+// Keys returns the sorted keys of the data attached to the error.
+func (e *Error[T]) Keys() []string {
+	return slices.Sorted(maps.Keys(e.data))
+}
+
+// exportedData returns a copy of the data with sensitive values redacted.
+func (e *Error[T]) exportedData() map[string]any {
+	data := maps.Clone(e.data)
+	if hook := dataValueHook.Load(); hook != nil {
+		for k, v := range data {
+			if _, ok := e.sensitive[k]; !ok {
+				data[k] = (*hook)(k, v)
+			}
+		}
+	}
+	for k := range e.sensitive {
+		if _, ok := data[k]; ok {
+			data[k] = redacted
+		}
+	}
+	return data
+}
+
 // WithError wraps an existing error.
+// Wrapping context.Canceled adds the TagCanceled tag, and wrapping
+// context.DeadlineExceeded adds TagTimeout and marks the error as a timeout.
+// Wrapping an error that already wraps e is refused and leaves e unchanged,
+// since it would create a cycle.
 func (e *Error[T]) WithError(err error) *Error[T] {
+	err = orNil(err)
+	if e.wrap(err) && err != nil {
+		e.recordWrapSite(1)
+	}
+	return e
+}
+
+// WithErrorf wraps err under a formatted message, rendered as "code: message: err".
+// Unlike Errorf, it keeps err as the wrapped error, so errors.Is(e, err) holds.
+func (e *Error[T]) WithErrorf(err error, format string, a ...any) *Error[T] {
+	e.message = fmt.Sprintf(format, a...)
+	err = orNil(err)
+	if e.wrap(err) && err != nil {
+		e.recordWrapSite(1)
+	}
+	return e
+}
+
+// WithErrors wraps several existing errors at once, replacing any previously
+// wrapped error. errors.Is and errors.As traverse every one of them, and tags
+// are propagated from each. Nil errors, and errors that would create a cycle, are ignored.
+func (e *Error[T]) WithErrors(errs ...error) *Error[T] {
+	errs = slices.DeleteFunc(slices.Clone(errs), func(err error) bool {
+		return orNil(err) == nil || reaches(err, e)
+	})
+	if len(errs) == 0 {
+		return e
+	}
+
+	for _, err := range errs {
+		e.wrap(err)
+	}
+	if len(errs) > 1 {
+		e.wrappedErr = &joinedError{errs: errs}
+	}
+	e.recordWrapSite(1)
+	return e
+}
+
+func (e *Error[T]) isNil() bool {
+	return e == nil
+}
+
+// orNil returns nil for a nil *Error of any code type held in a non-nil error
+// interface, such as the result of Wrap(nil, code), and err otherwise.
+func orNil(err error) error {
+	if n, ok := err.(chainNode); ok && n.isNil() {
+		return nil
+	}
+	return err
+}
+
+// wrap sets err as the wrapped error and propagates its tags.
+// It reports false, leaving e unchanged, if wrapping err would create a cycle.
+func (e *Error[T]) wrap(err error) bool {
+	if err != nil && reaches(err, e) {
+		return false
+	}
 	e.wrappedErr = err
 
 	// Propagate the tags
-	if wrappedErr, ok := err.(interface{ GetTags() []string }); ok {
-		e.tags.Add(wrappedErr.GetTags()...)
+	if wrappedErr, ok := err.(interface{ GetTags() []string }); ok && !e.noPropagateTags {
+		e.addTags(wrappedErr.GetTags()...)
+	}
+
+	// Classify context errors
+	if !e.noContextTags {
+		if errors.Is(err, context.Canceled) {
+			e.addTags(TagCanceled)
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			e.addTags(TagTimeout)
+			e.timeout = true
+		}
 	}
 
+	return true
+}
+
+// WithCode replaces the code of e, keeping its message, data, tags, wrapped error
+// and stack, e.g. to demote an internal error to a client-facing code.
+// This changes the identity of e: errors.Is no longer matches it against the old code.
+func (e *Error[T]) WithCode(code T) *Error[T] {
+	e.code = code
+	return e
+}
+
+// PropagateTags controls whether the later WithError and WithErrors calls on e
+// add the tags of the wrapped errors to e, which they do by default.
+// Context error tags are controlled separately, see WithoutContextTags.
+func (e *Error[T]) PropagateTags(propagate bool) *Error[T] {
+	e.noPropagateTags = !propagate
 	return e
 }
 
-// Errorf formats and wraps an error message.
+// Timeout reports whether the error wraps context.DeadlineExceeded.
+func (e *Error[T]) Timeout() bool {
+	return e.timeout
+}
+
+// Temporary reports whether the error is worth retrying, which is the case for timeouts.
+func (e *Error[T]) Temporary() bool {
+	return e.timeout
+}
+
+// recordWrapSite records the location of a wrapping call, skipping 'skip' frames
+// above the caller of recordWrapSite, if the error was created with WithWrapSites.
+func (e *Error[T]) recordWrapSite(skip int) {
+	if !e.recordWrapSites {
+		return
+	}
+	if frame, ok := captureFrame(skip + 1); ok {
+		e.wrapSites = append(e.wrapSites, frame)
+	}
+}
+
+// GoroutineID returns the ID of the goroutine that created the error, or 0 if the
+// error was not created with the WithGoroutineID option.
+//
+// Goroutine IDs are meant for debugging only: they are reused by the runtime and
+// are not stable identifiers.
+func (e *Error[T]) GoroutineID() uint64 {
+	return e.goroutineID
+}
+
+// CreatedAt returns the time the error was created, or the zero time if the
+// error was not created with the WithTimestamp option.
+func (e *Error[T]) CreatedAt() time.Time {
+	return e.createdAt
+}
+
+// WrapSites returns the locations where errors were wrapped into e, in call order.
+// They are only recorded for errors created with the WithWrapSites option.
+func (e *Error[T]) WrapSites() []Frame {
+	return slices.Clone(e.wrapSites)
+}
+
+// Merge returns a new Error combining e and other: it has e's code, the union of
+// both tag sets, the data of both with e winning on conflicting keys, and both
+// wrapped errors, joined as by WithErrors. It keeps e's stack, message and options,
+// falling back to other's message when e has none. Neither e nor other is modified.
+func (e *Error[T]) Merge(other *Error[T]) *Error[T] {
+	merged := *e
+	merged.data = maps.Clone(e.data)
+	merged.sensitive = maps.Clone(e.sensitive)
+	merged.tags = nil
+	merged.addTags(e.GetTags()...)
+	merged.wrapSites = slices.Clone(e.wrapSites)
+	if other == nil {
+		return &merged
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(other.data)) {
+		if _, ok := merged.data[k]; !ok && merged.admitData(k) {
+			merged.data[k] = other.data[k]
+		}
+	}
+	for k := range other.sensitive {
+		if merged.sensitive == nil {
+			merged.sensitive = map[string]struct{}{}
+		}
+		merged.sensitive[k] = struct{}{}
+	}
+	merged.addTags(other.GetTags()...)
+	if merged.message == "" {
+		merged.message = other.message
+	}
+	merged.timeout = e.timeout || other.timeout
+	merged.dataTruncated = merged.dataTruncated || other.dataTruncated
+
+	var wrapped []error
+	for _, err := range []error{e.wrappedErr, other.wrappedErr} {
+		if joined, ok := err.(*joinedError); ok {
+			wrapped = append(wrapped, joined.errs...)
+		} else if err != nil {
+			wrapped = append(wrapped, err)
+		}
+	}
+	switch len(wrapped) {
+	case 0:
+		merged.wrappedErr = nil
+	case 1:
+		merged.wrappedErr = wrapped[0]
+	default:
+		merged.wrappedErr = &joinedError{errs: wrapped}
+	}
+
+	return &merged
+}
+
+// Errorf formats and wraps an error message, replacing any previously wrapped error.
+// Use WithErrorf to add a message while keeping the wrapped error.
 func (e *Error[T]) Errorf(format string, a ...any) *Error[T] {
 	e.wrappedErr = fmt.Errorf(format, a...)
 	return e
@@ -111,30 +601,267 @@ func (e *Error[T]) Errorf(format string, a ...any) *Error[T] {
 //
 //nolint:ireturn // This is fine
 func (e *Error[T]) Code() T {
+	if e == nil {
+		return ""
+	}
 	return e.code
 }
 
+// CodeString returns the error code as a plain string.
+// The conversion shares the code's bytes and does not allocate.
 func (e *Error[T]) CodeString() string {
+	if e == nil {
+		return ""
+	}
 	return string(e.code)
 }
 
+// WithStack captures a stack starting at the caller, replacing any existing one.
+// Combined with WithoutStack, it defers the cost of a stack to the errors that need one.
+func (e *Error[T]) WithStack() *Error[T] {
+	e.stack = captureStack(1, defaultStackDepth)
+	return e
+}
+
+// HasStack reports whether a stack was captured for the error. It is false for
+// errors created with WithoutStack, or when no frames could be captured.
+func (e *Error[T]) HasStack() bool {
+	return e != nil && e.stack != nil && len(e.stack.pcs) > 0
+}
+
+// Caller returns the location where the error was created, taken from the
+// top frame of the captured stack. ok is false when no stack was captured.
+func (e *Error[T]) Caller() (file string, line int, function string, ok bool) {
+	if e.stack == nil || len(e.stack.resolved()) == 0 {
+		return "", 0, "", false
+	}
+	frame := e.stack.resolved()[0]
+	return frame.file, frame.line, frame.function, true
+}
+
+// StackFrames returns the frames of the stack captured when the error was created,
+// without runtime and testing frames.
+func (e *Error[T]) StackFrames() []Frame {
+	if e.stack == nil {
+		return nil
+	}
+	resolved := e.stack.resolved()
+	frames := make([]Frame, 0, len(resolved))
+	for _, frame := range resolved {
+		frames = append(frames, frame.frame())
+	}
+	return frames
+}
+
+// StackTrace returns the program counters captured when the error was created,
+// in the shape expected by error reporters such as Sentry. Unlike the rendered
+// stack, it is unfiltered and includes runtime frames.
+func (e *Error[T]) StackTrace() []uintptr {
+	if e.stack == nil {
+		return nil
+	}
+	return slices.Clone(e.stack.pcs)
+}
+
 // Error implements the error interface.
+// A chain of Errors is rendered in a single pass into one buffer.
 func (e *Error[T]) Error() string {
-	if e.wrappedErr != nil {
-		return fmt.Sprintf("%s: %s", e.code, e.wrappedErr.Error())
+	if e == nil {
+		return ""
+	}
+	if e.message == "" && e.wrappedErr == nil {
+		return string(e.code)
+	}
+
+	// Measure the chain first, rendering the first error of another type only once.
+	size := 0
+	var tail string
+	for current := error(e); current != nil; {
+		p, ok := current.(chainNode)
+		if !ok {
+			tail = current.Error()
+			size += len(tail)
+			break
+		}
+		code, message, wrapped := p.messageParts()
+		size += len(code) + len(message) + 4 //nolint:mnd // two ": " separators
+		current = wrapped
+	}
+
+	var sb strings.Builder
+	sb.Grow(size)
+	for current := error(e); current != nil; {
+		p, ok := current.(chainNode)
+		if !ok {
+			sb.WriteString(tail)
+			break
+		}
+		code, message, wrapped := p.messageParts()
+		sb.WriteString(code)
+		if message != "" {
+			sb.WriteString(": ")
+			sb.WriteString(message)
+		}
+		if wrapped != nil {
+			sb.WriteString(": ")
+		}
+		current = wrapped
+	}
+	return sb.String()
+}
+
+// messageParts returns the parts of e that Error renders, so that Error can
+// render a chain of Errors without recursing.
+func (e *Error[T]) messageParts() (string, string, error) {
+	if e == nil {
+		return "", "", nil
+	}
+	return string(e.code), e.message, e.wrappedErr
+}
+
+// VerboseError renders the chain like Error, with the tags and data of each Error
+// after its code, e.g. "not_found [tags=authz,iam] {user_id=123}: message".
+// Tags and data keys are sorted so the output is deterministic, and sensitive
+// values are redacted. It is meant for humans, LogValue remains the structured form.
+func (e *Error[T]) VerboseError() string {
+	if e == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	writeVerbose(&sb, e)
+	return sb.String()
+}
+
+// writeVerbose renders err for VerboseError. The branches of WithErrors are
+// each rendered verbosely and joined with "; ", like joinedError.Error.
+func writeVerbose(sb *strings.Builder, err error) {
+	for current := err; current != nil; {
+		if j, ok := current.(*joinedError); ok {
+			for i, branch := range j.errs {
+				if i > 0 {
+					sb.WriteString("; ")
+				}
+				writeVerbose(sb, branch)
+			}
+			return
+		}
+		h, ok := current.(chainNode)
+		if !ok {
+			sb.WriteString(current.Error())
+			return
+		}
+		_, message, wrapped := h.messageParts()
+		sb.WriteString(h.verboseHead())
+		if message != "" {
+			sb.WriteString(": ")
+			sb.WriteString(message)
+		}
+		if wrapped != nil {
+			sb.WriteString(": ")
+		}
+		current = wrapped
 	}
-	return string(e.code)
+}
+
+// verboseHead renders the code, tags and data of e for VerboseError.
+func (e *Error[T]) verboseHead() string {
+	if e == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(string(e.code))
+	if tags := e.GetTags(); len(tags) > 0 {
+		slices.Sort(tags)
+		sb.WriteString(" [tags=")
+		sb.WriteString(strings.Join(tags, ","))
+		sb.WriteString("]")
+	}
+	if len(e.data) > 0 {
+		data := e.exportedData()
+		sb.WriteString(" {")
+		for i, k := range slices.Sorted(maps.Keys(data)) {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "%s=%v", k, data[k])
+		}
+		sb.WriteString("}")
+	}
+	return sb.String()
+}
+
+// Equal reports whether e and other have the same code, data and tags.
+// Data values are compared with reflect.DeepEqual and tags as sets.
+// The stack, message and wrapped error are excluded from equality.
+//
+// Because of its signature, go-cmp uses this method automatically when
+// comparing two *Error[T] values, so no extra cmp.Option is needed.
+func (e *Error[T]) Equal(other *Error[T]) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	if e.code != other.code {
+		return false
+	}
+	if !reflect.DeepEqual(e.data, other.data) {
+		return false
+	}
+	tags, otherTags := e.GetTags(), other.GetTags()
+	return len(tags) == len(otherTags) && e.HasAllTags(otherTags...)
 }
 
 // Unwrap implements error unwrapping.
 func (e *Error[T]) Unwrap() error {
+	if e == nil {
+		return nil
+	}
 	return e.wrappedErr
 }
 
+// NewMatcher creates a sentinel for errors.Is that matches errors with the given code
+// and all of the required tags, e.g. to tell permission_denied{admin} from
+// permission_denied{user}. The sentinel has no stack.
+func NewMatcher[T ~string](code T, requiredTags ...string) *Error[T] {
+	e := newWithSkip(code, 1, WithoutStack(), WithInitialTags(requiredTags...))
+	e.matchTags = true
+	return e
+}
+
+// Code returns a sentinel error for errors.Is that matches any Error with the
+// given code, whatever its code type, e.g. errors.Is(err, zerrors.Code(ErrNotFound)).
+func Code[T ~string](code T) error {
+	return codeSentinel{code: string(code)}
+}
+
+// codeSentinel is the error returned by Code.
+type codeSentinel struct {
+	code string
+}
+
+func (c codeSentinel) Error() string {
+	return c.code
+}
+
+// Is matches Errors of any code type with the code of c.
+func (c codeSentinel) Is(target error) bool {
+	t, ok := target.(chainNode)
+	return ok && t.CodeString() == c.code
+}
+
 // Is implements error comparison.
+// Errors match when their codes are equal. If target was created by NewMatcher,
+// e must also have all of its tags. If target was returned by Code, only the
+// codes are compared, as strings.
 func (e *Error[T]) Is(target error) bool {
+	if c, ok := target.(codeSentinel); ok {
+		return e != nil && string(e.code) == c.code
+	}
 	t, ok := target.(*Error[T])
-	if !ok {
+	if !ok || e == nil || t == nil {
+		return false
+	}
+	if t.matchTags && !e.HasAllTags(t.GetTags()...) {
 		return false
 	}
 	return e.code == t.code
@@ -142,6 +869,9 @@ func (e *Error[T]) Is(target error) bool {
 
 // As implements error casting.
 func (e *Error[T]) As(target any) bool {
+	if e == nil {
+		return false
+	}
 	if targetErr, ok := target.(**Error[T]); ok {
 		*targetErr = e
 		return true
@@ -156,6 +886,63 @@ func (e *Error[T]) As(target any) bool {
 	return false
 }
 
+// joinedError holds the errors wrapped by WithErrors.
+// It implements Unwrap() []error, since Error keeps its single-error Unwrap.
+type joinedError struct {
+	errs []error
+}
+
+func (j *joinedError) Error() string {
+	msgs := make([]string, 0, len(j.errs))
+	for _, err := range j.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (j *joinedError) Unwrap() []error {
+	return j.errs
+}
+
+func (j *joinedError) LogValue() slog.Value {
+	return j.chainLogValue(nil, 0)
+}
+
+func (j *joinedError) chainLogValue(parent *stack, depth int) slog.Value {
+	attrs := make([]slog.Attr, 0, len(j.errs))
+	for i, err := range j.errs {
+		attrs = append(attrs, slog.Any(strconv.Itoa(i), wrappedLogValue(err, parent, depth)))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// chainLogValuer is implemented by the errors of this package, so that a wrapped
+// error can be rendered relative to the error wrapping it.
+type chainLogValuer interface {
+	chainLogValue(parent *stack, depth int) slog.Value
+}
+
+// wrappedLogValue renders an error found at the given depth of the chain, wrapped
+// by an error whose stack is parent, recursing into errors that implement slog.LogValuer.
+// Past the maximum depth, the rest of the chain is replaced by a marker.
+func wrappedLogValue(err error, parent *stack, depth int) slog.Value {
+	if depth >= currentMaxLogDepth() {
+		return slog.StringValue(fmt.Sprintf("... truncated (%d more)", chainLen(err)))
+	}
+	if c, ok := err.(chainLogValuer); ok {
+		return c.chainLogValue(parent, depth)
+	}
+	if render := wrappedRenderer.Load(); render != nil {
+		if v := (*render)(err); !v.Equal(slog.Value{}) {
+			return v
+		}
+	}
+	if logValuer, ok := err.(slog.LogValuer); ok {
+		return logValuer.LogValue()
+	}
+	return slog.StringValue(err.Error())
+}
+
 // As implements error casting with a callback.
 func As[T ~string, V any](err error, fn func(zerr *Error[T]) V) (*V, bool) {
 	var zerr *Error[T]
@@ -167,10 +954,38 @@ func As[T ~string, V any](err error, fn func(zerr *Error[T]) V) (*V, bool) {
 	return empty, false
 }
 
-func HasCode[T ~string](err error, code T) bool {
-	var e *Error[T]
-	if errors.As(err, &e) {
-		return e.Code() == code
+// Inspect calls fn with the first *Error[T] in the chain of err, if any,
+// and reports whether it was found. Use As to project a value instead.
+func Inspect[T ~string](err error, fn func(zerr *Error[T])) bool {
+	zerr, ok := AsError[T](err)
+	if ok {
+		fn(zerr)
 	}
-	return false
+	return ok
+}
+
+// AsError finds the first *Error[T] in the chain of err.
+func AsError[T ~string](err error) (*Error[T], bool) {
+	var zerr *Error[T]
+	if errors.As(err, &zerr) {
+		return zerr, true
+	}
+	return nil, false
+}
+
+// FindByCode finds the first *Error[T] in the chain of err whose code is code.
+// Unlike AsError, it skips the Errors of the same type carrying other codes.
+func FindByCode[T ~string](err error, code T) (*Error[T], bool) {
+	for e := range Iter(err) {
+		if zerr, ok := e.(*Error[T]); ok && zerr.code == code {
+			return zerr, true
+		}
+	}
+	return nil, false
+}
+
+// HasCode reports whether any *Error[T] in the chain of err has the given code.
+func HasCode[T ~string](err error, code T) bool {
+	_, ok := FindByCode(err, code)
+	return ok
 }
@@ -1,8 +1,18 @@
 package zerrors_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DeluxeOwl/zerrors"
 	"github.com/stretchr/testify/require"
@@ -91,5 +101,2028 @@ func Test_Tags(t *testing.T) {
 		require.True(t, derr.HasTags("authz"))
 		require.True(t, derr.HasTags("permission"))
 		require.ElementsMatch(t, []string{"permission", "iam", "authz"}, derr.GetTags())
+
+		require.True(t, derr.HasAllTags("iam", "permission"))
+		require.False(t, derr.HasAllTags("iam", "transient"))
+		require.True(t, derr.HasAnyTags("transient", "permission"))
+		require.False(t, derr.HasAnyTags("transient", "network"))
+		require.False(t, derr.HasAnyTags())
+	}
+}
+
+func Test_Wrap(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	require.Nil(t, zerrors.Wrap(nil, domainErrNotFound))
+	require.Nil(t, zerrors.Wrapf(nil, domainErrNotFound, "user %d", 123))
+
+	errDB := zerrors.New(dbErrZeroRows).Tags("database")
+
+	err := zerrors.Wrap(errDB, domainErrNotFound)
+	require.Equal(t, domainErrNotFound, err.Code())
+	require.Equal(t, "not_found: zero_rows", err.Error())
+	require.True(t, err.HasTags("database"))
+	require.ErrorIs(t, err, errDB)
+	require.True(t, zerrors.HasCode(err, dbErrZeroRows))
+
+	errf := zerrors.Wrapf(errDB, domainErrNotFound, "user %d", 123)
+	require.Equal(t, "not_found: user 123: zero_rows", errf.Error())
+	require.ErrorIs(t, errf, errDB)
+
+	// Layered wraps across code types keep success as success.
+	var queryErr error
+	query := func() error { return queryErr }
+	repo := func() error { return zerrors.Wrap(query(), dbErrZeroRows) }
+	svc := func() error { return zerrors.Wrap(repo(), domainErrNotFound) }
+
+	require.Nil(t, zerrors.Wrap(repo(), domainErrNotFound))
+	require.Nil(t, zerrors.Wrapf(repo(), domainErrNotFound, "user %d", 123))
+	require.Nil(t, zerrors.Coerce(repo(), domainErrNotFound))
+	require.Nil(t, zerrors.Annotate(repo(), domainErrNotFound))
+	require.Nil(t, svc())
+	require.Empty(t, zerrors.Codes(svc()))
+
+	queryErr = errors.New("no rows")
+	require.Equal(t, "not_found: zero_rows: no rows", svc().Error())
+}
+
+func renderedStack(t *testing.T, v slog.LogValuer) string {
+	t.Helper()
+
+	for _, attr := range v.LogValue().Group() {
+		if attr.Key == "stack" {
+			return attr.Value.String()
+		}
+	}
+	t.Fatal("no stack attribute")
+	return ""
+}
+
+func firstStackFrame(t *testing.T, v slog.LogValuer) string {
+	t.Helper()
+
+	frames := strings.Split(strings.TrimPrefix(renderedStack(t, v), "\n    at "), "\n    at ")
+	return frames[0]
+}
+
+func Test_StackSkip(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	cause := errors.New("cause")
+
+	for name, err := range map[string]*zerrors.Error[domainErr]{
+		"New":   zerrors.New(domainErrNotFound),
+		"Newf":  zerrors.Newf(domainErrNotFound, "message"),
+		"Wrap":  zerrors.Wrap(cause, domainErrNotFound),
+		"Wrapf": zerrors.Wrapf(cause, domainErrNotFound, "message"),
+	} {
+		frame := firstStackFrame(t, err)
+		require.True(t, strings.HasSuffix(frame, " Test_StackSkip()"), "%s: unexpected first frame %q", name, frame)
+	}
+}
+
+func Test_Newf(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	err := zerrors.Newf(domainErrNotFound, "user %d missing", 123)
+	require.Equal(t, domainErrNotFound, err.Code())
+	require.Equal(t, "not_found: user 123 missing", err.Error())
+	require.NoError(t, err.Unwrap())
+
+	cause := errors.New("no rows")
+	err = err.WithError(cause)
+	require.Equal(t, "not_found: user 123 missing: no rows", err.Error())
+	require.ErrorIs(t, err, cause)
+}
+
+func Test_Caller(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	file, line, function, ok := zerrors.New(domainErrNotFound).Caller()
+	require.True(t, ok)
+	require.True(t, strings.HasSuffix(file, "error_test.go"))
+	require.Positive(t, line)
+	require.Equal(t, "Test_Caller", function)
+
+	_, _, _, ok = (&zerrors.Error[domainErr]{}).Caller()
+	require.False(t, ok)
+}
+
+func Test_Equal(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound   domainErr = "not_found"
+		domainErrBadRequest domainErr = "bad_request"
+	)
+
+	newErr := func() *zerrors.Error[domainErr] {
+		return zerrors.New(domainErrNotFound).
+			With("user_id", 123).
+			With("ids", []int{1, 2}).
+			Tags("iam", "authz")
+	}
+
+	require.True(t, newErr().Equal(newErr()))
+	require.True(t, newErr().Equal(newErr().Tags("authz").WithError(errors.New("cause"))))
+	require.False(t, newErr().Equal(zerrors.New(domainErrBadRequest).With("user_id", 123).With("ids", []int{1, 2}).Tags("iam", "authz")))
+	require.False(t, newErr().Equal(newErr().With("user_id", 456)))
+	require.False(t, newErr().Equal(newErr().Tags("admin")))
+
+	var nilErr *zerrors.Error[domainErr]
+	require.True(t, nilErr.Equal(nil))
+	require.False(t, newErr().Equal(nil))
+}
+
+func Test_Codes(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	errDB := zerrors.New(dbErrZeroRows)
+	err := zerrors.New(domainErrNotFound).WithError(fmt.Errorf("query: %w", errDB))
+
+	require.Equal(t, []string{"not_found", "zero_rows"}, zerrors.Codes(err))
+	require.Equal(t, []string{"zero_rows"}, zerrors.Codes(zerrors.Wrap(errDB, dbErrZeroRows)))
+	require.Empty(t, zerrors.Codes(errors.New("plain")))
+	require.Empty(t, zerrors.Codes(nil))
+}
+
+func Test_JoinedChains(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	joined := errors.Join(
+		zerrors.New(domainErrNotFound).Tags("iam").With("user_id", 123),
+		zerrors.New(dbErrZeroRows).Tags("database").With("query", "SELECT 1"),
+	)
+	err := fmt.Errorf("batch: %w", joined)
+
+	require.Equal(t, []string{"not_found", "zero_rows"}, zerrors.Codes(err))
+	require.Equal(t, []string{"database", "iam"}, zerrors.AllTags(err))
+	require.Equal(t, map[string]any{"user_id": 123, "query": "SELECT 1"}, zerrors.FlattenData(err))
+	require.True(t, zerrors.HasCodeString(err, "zero_rows"))
+	require.True(t, zerrors.EveryTag(err, "iam", "database"))
+}
+
+func Test_HasCodeString(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	err := zerrors.New(domainErrNotFound).WithError(fmt.Errorf("query: %w", zerrors.New(dbErrZeroRows)))
+
+	require.True(t, zerrors.HasCodeString(err, "not_found"))
+	require.True(t, zerrors.HasCodeString(err, "zero_rows"))
+	require.False(t, zerrors.HasCodeString(err, "query"))
+	require.False(t, zerrors.HasCodeString(nil, ""))
+}
+
+func Test_AllTags(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	errDB := zerrors.New(dbErrZeroRows).Tags("database")
+	err := zerrors.New(domainErrNotFound).Tags("iam").WithError(errDB)
+
+	errDB.Tags("transient", "iam")
+
+	require.ElementsMatch(t, []string{"iam", "database"}, err.GetTags())
+	require.Equal(t, []string{"database", "iam", "transient"}, zerrors.AllTags(err))
+	require.Empty(t, zerrors.AllTags(errors.New("plain")))
+}
+
+func Test_AnyTagEveryTag(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	errDB := zerrors.New(dbErrZeroRows).Tags("database")
+	err := zerrors.New(domainErrNotFound).WithError(fmt.Errorf("query: %w", errDB)).Tags("iam")
+
+	require.False(t, err.HasAnyTags("database"))
+	require.True(t, zerrors.AnyTag(err, "database"))
+	require.True(t, zerrors.AnyTag(err, "http", "iam"))
+	require.False(t, zerrors.AnyTag(err, "http"))
+
+	require.True(t, zerrors.EveryTag(err, "iam", "database"))
+	require.False(t, zerrors.EveryTag(err, "iam", "http"))
+
+	require.False(t, zerrors.AnyTag(errors.New("plain"), "iam"))
+	require.False(t, zerrors.AnyTag(nil, "iam"))
+}
+
+func Test_WithSensitive(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrUnauthorized domainErr = "unauthorized"
+		domainErrNotFound     domainErr = "not_found"
+	)
+
+	inner := zerrors.New(domainErrUnauthorized).
+		With("user_id", 123).
+		WithSensitive("token", "s3cr3t-token")
+	err := zerrors.New(domainErrNotFound).
+		WithSensitive("email", "jane@example.com").
+		WithError(inner)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", slog.Any("error", err))
+
+	require.NotContains(t, buf.String(), "s3cr3t-token")
+	require.NotContains(t, buf.String(), "jane@example.com")
+	require.Contains(t, buf.String(), `"email":"[REDACTED]"`)
+	require.Contains(t, buf.String(), `"token":"[REDACTED]"`)
+	require.Contains(t, buf.String(), `"user_id":123`)
+
+	email, ok := err.Get("email")
+	require.True(t, ok)
+	require.Equal(t, "jane@example.com", email)
+}
+
+func Test_StackTrace(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	pcs := zerrors.New(domainErrNotFound).StackTrace()
+	require.NotEmpty(t, pcs)
+
+	frames := runtime.CallersFrames(pcs)
+	first, _ := frames.Next()
+	require.Equal(t, "github.com/DeluxeOwl/zerrors_test.Test_StackTrace", first.Function)
+	require.True(t, strings.HasSuffix(first.File, "error_test.go"))
+
+	require.Nil(t, (&zerrors.Error[domainErr]{}).StackTrace())
+}
+
+func Test_SetMaxRenderedFrames(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	var recurse func(depth int) *zerrors.Error[domainErr]
+	recurse = func(depth int) *zerrors.Error[domainErr] {
+		if depth == 0 {
+			return zerrors.New(domainErrNotFound)
+		}
+		return recurse(depth - 1)
+	}
+	err := recurse(10)
+
+	full := renderedStack(t, err)
+	total := strings.Count(full, "\n    at ")
+	require.Greater(t, total, 3)
+	require.NotContains(t, full, "more)")
+
+	zerrors.SetMaxRenderedFrames(3)
+	t.Cleanup(func() { zerrors.SetMaxRenderedFrames(0) })
+
+	truncated := renderedStack(t, err)
+	require.Equal(t, 3, strings.Count(truncated, "\n    at "))
+	require.True(t, strings.HasSuffix(truncated, fmt.Sprintf("\n    ... (%d more)", total-3)))
+}
+
+func Test_SetSingleLineStack(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	zerrors.SetSingleLineStack(true)
+	t.Cleanup(func() { zerrors.SetSingleLineStack(false) })
+
+	var err *zerrors.Error[domainErr]
+	func() { err = zerrors.New(domainErrNotFound) }()
+	frames := err.StackFrames()
+	require.Len(t, frames, 2)
+
+	require.Equal(t, frames[0].String()+" <- "+frames[1].String(), renderedStack(t, err))
+
+	zerrors.SetMaxRenderedFrames(1)
+	t.Cleanup(func() { zerrors.SetMaxRenderedFrames(0) })
+	require.Equal(t, frames[0].String()+" <- ... (1 more)", renderedStack(t, err))
+}
+
+func Test_Key(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	userID := zerrors.NewKey[int]("user_id")
+	query := zerrors.NewKey[string]("query")
+	require.Equal(t, "user_id", userID.Name())
+
+	errDB := zerrors.WithKey(zerrors.New(dbErrZeroRows), query, "SELECT 1")
+	err := zerrors.WithKey(zerrors.New(domainErrNotFound), userID, 123).WithError(fmt.Errorf("lookup: %w", errDB))
+
+	id, ok := zerrors.GetKey(err, userID)
+	require.True(t, ok)
+	require.Equal(t, 123, id)
+
+	q, ok := zerrors.GetKey(err, query)
+	require.True(t, ok)
+	require.Equal(t, "SELECT 1", q)
+
+	// Values of another type under the same name are not returned.
+	_, ok = zerrors.GetKey(zerrors.New(domainErrNotFound).With("user_id", "123"), userID)
+	require.False(t, ok)
+
+	require.Equal(t, map[string]any{"user_id": int64(123)}, zerrors.ToMap(err)["data"])
+}
+
+func Test_GetData(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	err := zerrors.New(domainErrNotFound).
+		With("user_id", 123).
+		With("attempt", 1)
+
+	data := err.GetData()
+	require.Equal(t, map[string]any{"user_id": 123, "attempt": 1}, data)
+	require.Equal(t, []string{"attempt", "user_id"}, err.Keys())
+
+	data["user_id"] = 456
+	data["extra"] = true
+	userID, _ := err.Get("user_id")
+	require.Equal(t, 123, userID)
+	_, ok := err.Get("extra")
+	require.False(t, ok)
+}
+
+func Test_HasDelete(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	err := zerrors.New(domainErrNotFound).
+		With("user_id", 123).
+		With("attempt", 1)
+
+	require.True(t, err.Has("attempt"))
+	require.False(t, err.Has("missing"))
+
+	err = err.Delete("attempt").Delete("missing")
+	require.False(t, err.Has("attempt"))
+	require.True(t, err.Has("user_id"))
+	require.Equal(t, []string{"user_id"}, err.Keys())
+}
+
+func Test_WithErrors(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrTxFailed domainErr = "tx_failed"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrConflict       dbErr = "conflict"
+		dbErrRollbackFailed dbErr = "rollback_failed"
+	)
+
+	errConflict := zerrors.New(dbErrConflict).Tags("database")
+	errRollback := zerrors.New(dbErrRollbackFailed).Tags("critical")
+	closed := errors.New("conn closed")
+
+	err := zerrors.New(domainErrTxFailed).WithErrors(errConflict, nil, errRollback, fmt.Errorf("rollback: %w", closed))
+
+	require.Equal(t, "tx_failed: conflict; rollback_failed; rollback: conn closed", err.Error())
+	require.ErrorIs(t, err, errConflict)
+	require.ErrorIs(t, err, errRollback)
+	require.ErrorIs(t, err, closed)
+	require.ElementsMatch(t, []string{"database", "critical"}, err.GetTags())
+
+	var rollbackErr *zerrors.Error[dbErr]
+	require.ErrorAs(t, err, &rollbackErr)
+	require.Equal(t, dbErrConflict, rollbackErr.Code())
+
+	wrapped, ok := errors.Unwrap(err).(interface{ Unwrap() []error })
+	require.True(t, ok)
+	require.Len(t, wrapped.Unwrap(), 3)
+
+	single := zerrors.New(domainErrTxFailed).WithErrors(nil, closed)
+	require.Equal(t, closed, errors.Unwrap(single))
+}
+
+func Test_WrapCycle(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrA domainErr = "a"
+		domainErrB domainErr = "b"
+	)
+
+	a := zerrors.New(domainErrA)
+	b := zerrors.New(domainErrB).WithError(fmt.Errorf("via: %w", a))
+
+	require.NoError(t, a.WithError(a).Unwrap())
+	require.NoError(t, a.WithError(b).Unwrap())
+
+	other := errors.New("other")
+	require.Equal(t, other, a.WithErrors(b, other).Unwrap())
+
+	done := make(chan string, 1)
+	go func() { done <- b.Error() }()
+
+	select {
+	case msg := <-done:
+		require.Equal(t, "b: via: a", msg)
+	case <-time.After(time.Second):
+		t.Fatal("Error() did not return")
+	}
+	require.Equal(t, []string{"b", "a"}, zerrors.Codes(b))
+}
+
+func Test_Options(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	data := map[string]any{"service": "billing"}
+	err := zerrors.New(domainErrNotFound,
+		zerrors.WithInitialTags("billing"),
+		zerrors.WithInitialTags("critical"),
+		zerrors.WithInitialData(data),
+		zerrors.WithStackDepth(2),
+	)
+
+	data["service"] = "changed"
+	service, ok := err.Get("service")
+	require.True(t, ok)
+	require.Equal(t, "billing", service)
+	require.ElementsMatch(t, []string{"billing", "critical"}, err.GetTags())
+	require.Len(t, err.StackTrace(), 2)
+
+	_, _, _, ok = zerrors.New(domainErrNotFound, zerrors.WithoutStack()).Caller()
+	require.False(t, ok)
+
+	_, _, function, ok := zerrors.New(domainErrNotFound).Caller()
+	require.True(t, ok)
+	require.Equal(t, "Test_Options", function)
+}
+
+func Test_SetOnCreate(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+		domainErrInvalid  domainErr = "invalid"
+	)
+
+	var (
+		mu    sync.Mutex
+		codes []string
+		tags  [][]string
+	)
+	zerrors.SetOnCreate(func(code string, t []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		codes = append(codes, code)
+		tags = append(tags, t)
+	})
+	t.Cleanup(func() { zerrors.SetOnCreate(nil) })
+
+	cause := zerrors.New(domainErrInvalid, zerrors.WithInitialTags("validation"))
+	zerrors.Newf(domainErrNotFound, "user %d", 1)
+	zerrors.Wrap(cause, domainErrNotFound)
+	zerrors.Wrapf(cause, domainErrNotFound, "user %d", 1)
+	zerrors.Wrap(nil, domainErrNotFound)
+
+	require.Equal(t, []string{"invalid", "not_found", "not_found", "not_found"}, codes)
+	require.Equal(t, []string{"validation"}, tags[0])
+	require.Empty(t, tags[1])
+	require.Equal(t, []string{"validation"}, tags[2])
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			zerrors.New(domainErrNotFound)
+		}()
+	}
+	wg.Wait()
+	require.Len(t, codes, 14)
+}
+
+func Test_SetCollapseSharedFrames(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+		domainErrInvalid  domainErr = "invalid"
+	)
+
+	inner := func() error {
+		return zerrors.New(domainErrInvalid)
+	}
+	outer := func() *zerrors.Error[domainErr] {
+		return zerrors.New(domainErrNotFound).WithError(inner())
+	}
+	err := outer()
+
+	wrappedStack := func() string {
+		for _, attr := range err.LogValue().Group() {
+			if attr.Key == "wrapped" {
+				for _, wrappedAttr := range attr.Value.Group() {
+					if wrappedAttr.Key == "stack" {
+						return wrappedAttr.Value.String()
+					}
+				}
+			}
+		}
+		t.Fatal("no wrapped stack attribute")
+		return ""
+	}
+
+	full := wrappedStack()
+	require.NotContains(t, full, "more")
+
+	zerrors.SetCollapseSharedFrames(true)
+	t.Cleanup(func() { zerrors.SetCollapseSharedFrames(false) })
+
+	collapsed := wrappedStack()
+	require.True(t, strings.HasSuffix(collapsed, "\n    ... 1 more"), collapsed)
+	require.Equal(t, strings.Count(full, "\n    at ")-1, strings.Count(collapsed, "\n    at "))
+	require.Equal(t, strings.Count(renderedStack(t, err), "\n    at "), strings.Count(full, "\n    at ")-1)
+}
+
+func Test_ToMap(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	errDB := zerrors.New(dbErrZeroRows, zerrors.WithoutStack()).
+		With("query", "SELECT 1").
+		WithSensitive("dsn", "postgres://secret").
+		WithError(errors.New("no rows"))
+	err := zerrors.New(domainErrNotFound, zerrors.WithoutStack()).
+		With("user_id", 123).
+		Tags("iam").
+		WithError(errDB)
+
+	require.Equal(t, map[string]any{
+		"code":  "not_found",
+		"error": "not_found: zero_rows: no rows",
+		"data":  map[string]any{"user_id": int64(123)},
+		"tags":  []string{"iam"},
+		"wrapped": map[string]any{
+			"code":    "zero_rows",
+			"error":   "zero_rows",
+			"data":    map[string]any{"query": "SELECT 1", "dsn": "[REDACTED]"},
+			"wrapped": "no rows",
+		},
+	}, zerrors.ToMap(err))
+
+	m := zerrors.ToMap(zerrors.New(domainErrNotFound))
+	require.Contains(t, m, "stack")
+
+	require.Equal(t, map[string]any{"error": "plain"}, zerrors.ToMap(errors.New("plain")))
+	require.Nil(t, zerrors.ToMap(nil))
+}
+
+func Test_WithStackPackagePrefix(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	var err *zerrors.Error[domainErr]
+	func() {
+		err = zerrors.New(domainErrNotFound,
+			zerrors.WithStackPackagePrefix("github.com/acme/"),
+			zerrors.WithStackPackagePrefix("github.com/DeluxeOwl/zerrors_test.Test_WithStackPackagePrefix.func1"),
+		)
+	}()
+
+	rendered := renderedStack(t, err)
+	require.Equal(t, 1, strings.Count(rendered, "\n    at "), rendered)
+	require.True(t, strings.HasSuffix(rendered, " Test_WithStackPackagePrefix.func1()"), rendered)
+	require.Greater(t, len(err.StackTrace()), 1)
+
+	none := zerrors.New(domainErrNotFound, zerrors.WithStackPackagePrefix("github.com/acme/"))
+	require.Empty(t, renderedStack(t, none))
+}
+
+func Test_WithStackTrimBottom(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	var err *zerrors.Error[domainErr]
+	serveThrough(func() {
+		err = zerrors.New(domainErrNotFound, zerrors.WithStackTrimBottom("serveThrough"))
+	})
+
+	rendered := renderedStack(t, err)
+	require.Equal(t, 1, strings.Count(rendered, "\n    at "), rendered)
+	require.True(t, strings.HasSuffix(rendered, " Test_WithStackTrimBottom.func1()"), rendered)
+	require.Greater(t, len(err.StackFrames()), 2)
+
+	untrimmed := zerrors.New(domainErrNotFound, zerrors.WithStackTrimBottom("serveThrough"))
+	require.Equal(t, len(untrimmed.StackFrames()), strings.Count(renderedStack(t, untrimmed), "\n    at "))
+}
+
+// serveThrough stands for a router calling the application handler.
+func serveThrough(handler func()) {
+	handler()
+}
+
+func Test_Iter(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	leaf := errors.New("no rows")
+	errDB := zerrors.New(dbErrZeroRows).WithError(leaf)
+	wrapped := fmt.Errorf("query: %w", errDB)
+	err := zerrors.New(domainErrNotFound).WithError(wrapped)
+
+	var chain []error
+	for e := range zerrors.Iter(err) {
+		chain = append(chain, e)
+	}
+	require.Equal(t, []error{err, wrapped, errDB, leaf}, chain)
+
+	first := errors.New("first")
+	second := errors.New("second")
+	joined := errors.Join(fmt.Errorf("a: %w", first), second)
+	var messages []string
+	for e := range zerrors.Iter(fmt.Errorf("top: %w", joined)) {
+		messages = append(messages, e.Error())
+	}
+	require.Equal(t, []string{"top: a: first\nsecond", "a: first\nsecond", "a: first", "first", "second"}, messages)
+
+	for e := range zerrors.Iter(err) {
+		require.Equal(t, err, e)
+		break
+	}
+
+	for range zerrors.Iter(nil) {
+		t.Fatal("nil error yields nothing")
+	}
+}
+
+func Test_ChainSnapshot(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	errDB := zerrors.New(dbErrZeroRows).
+		With("query", "SELECT 1").
+		WithSensitive("dsn", "postgres://secret").
+		WithError(errors.New("no rows"))
+	err := zerrors.Newf(domainErrNotFound, "user %d", 123).
+		Tags("iam", "authz").
+		WithErrors(fmt.Errorf("lookup: %w", errDB), errors.New("closed"))
+
+	require.Equal(t, []zerrors.NodeSnapshot{
+		{Code: "not_found", Tags: []string{"authz", "iam"}, Message: "user 123"},
+		{Message: "lookup: zero_rows: no rows"},
+		{Code: "zero_rows", Data: map[string]any{"query": "SELECT 1", "dsn": "[REDACTED]"}},
+		{Message: "no rows"},
+		{Message: "closed"},
+	}, zerrors.ChainSnapshot(err))
+
+	require.Nil(t, zerrors.ChainSnapshot(nil))
+}
+
+func Test_LeavesCause(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	errTimeout := errors.New("timeout")
+	errRefused := errors.New("connection refused")
+	errClosed := errors.New("closed")
+
+	err := zerrors.New(domainErrNotFound).WithErrors(
+		fmt.Errorf("primary: %w", errors.Join(errTimeout, errRefused)),
+		zerrors.New(domainErrNotFound).WithError(errClosed),
+	)
+	require.Equal(t, []error{errTimeout, errRefused, errClosed}, zerrors.Leaves(err))
+	require.Equal(t, []error{errClosed}, zerrors.Leaves(errClosed))
+	require.Nil(t, zerrors.Leaves(nil))
+
+	linear := zerrors.New(domainErrNotFound).WithError(fmt.Errorf("lookup: %w", errClosed))
+	require.Same(t, errClosed, zerrors.Cause(linear))
+	require.Same(t, errClosed, zerrors.Cause(errClosed))
+	require.NoError(t, zerrors.Cause(nil))
+
+	// The cause of a multi-error is the error holding the branches.
+	cause := zerrors.Cause(err)
+	require.Len(t, zerrors.Leaves(cause), 3)
+}
+
+func Test_FlattenData(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	errDB := zerrors.New(dbErrZeroRows).
+		With("query", "SELECT 1").
+		With("attempt", 2).
+		WithSensitive("dsn", "postgres://secret")
+	err := zerrors.New(domainErrNotFound).
+		With("attempt", 1).
+		WithError(fmt.Errorf("lookup: %w", errDB))
+
+	require.Equal(t, map[string]any{
+		"query":   "SELECT 1",
+		"attempt": 1,
+		"dsn":     "[REDACTED]",
+	}, zerrors.FlattenData(err))
+	require.Empty(t, zerrors.FlattenData(errors.New("plain")))
+}
+
+func Test_NewMatcher(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrPermissionDenied domainErr = "permission_denied"
+		domainErrNotFound         domainErr = "not_found"
+	)
+
+	errAdminDenied := zerrors.NewMatcher(domainErrPermissionDenied, "admin")
+	errUserDenied := zerrors.NewMatcher(domainErrPermissionDenied, "user")
+
+	err := fmt.Errorf("handler: %w", zerrors.New(domainErrPermissionDenied).Tags("admin", "iam"))
+
+	require.ErrorIs(t, err, errAdminDenied)
+	require.NotErrorIs(t, err, errUserDenied)
+	require.NotErrorIs(t, err, zerrors.NewMatcher(domainErrNotFound, "admin"))
+	require.ErrorIs(t, err, zerrors.NewMatcher(domainErrPermissionDenied))
+
+	// The default comparison stays code-only.
+	require.ErrorIs(t, err, zerrors.New(domainErrPermissionDenied).Tags("user"))
+}
+
+func Test_Factory(t *testing.T) {
+	type billingErr string
+
+	const (
+		billingErrDeclined billingErr = "declined"
+	)
+
+	tags := []string{"billing"}
+	data := map[string]any{"service": "billing"}
+	factory := zerrors.NewFactory[billingErr](tags, data)
+	tags[0] = "changed"
+	data["service"] = "changed"
+
+	err1 := factory.New(billingErrDeclined).With("service", "override").Tags("card")
+	err2 := factory.New(billingErrDeclined, zerrors.WithInitialTags("retry"))
+
+	require.Equal(t, billingErrDeclined, err1.Code())
+	require.ElementsMatch(t, []string{"billing", "card"}, err1.GetTags())
+	require.ElementsMatch(t, []string{"billing", "retry"}, err2.GetTags())
+
+	service, _ := err2.Get("service")
+	require.Equal(t, "billing", service)
+
+	_, _, function, ok := err2.Caller()
+	require.True(t, ok)
+	require.Equal(t, "Test_Factory", function)
+}
+
+func Benchmark_Error(b *testing.B) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	err := zerrors.Newf(domainErrNotFound, "user %d", 123).WithError(errors.New("no rows"))
+
+	b.Run("sprintf", func(b *testing.B) {
+		// The formatting Error used before switching to a strings.Builder.
+		b.ReportAllocs()
+		for b.Loop() {
+			msg := fmt.Sprintf("%s: %s", err.Code(), "user 123")
+			_ = fmt.Sprintf("%s: %s", msg, err.Unwrap().Error())
+		}
+	})
+
+	b.Run("builder", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = err.Error()
+		}
+	})
+}
+
+func Test_Counter(t *testing.T) {
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+		dbErrTimeout  dbErr = "timeout"
+	)
+
+	counter := zerrors.NewCounter[dbErr]()
+
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch {
+			case i%10 == 0:
+				counter.Add(zerrors.New(dbErrTimeout).Tags("retry", "database"))
+			case i%25 == 1:
+				counter.Add(errors.New("plain"))
+			default:
+				counter.Add(fmt.Errorf("row %d: %w", i, zerrors.New(dbErrZeroRows)))
+			}
+		}()
+	}
+	counter.Add(nil)
+	wg.Wait()
+
+	require.Equal(t, []zerrors.CounterEntry{
+		{Code: "zero_rows", Count: 86},
+		{Code: "timeout", Tags: []string{"database", "retry"}, Count: 10},
+		{Code: "", Count: 4},
+	}, counter.Summary())
+}
+
+func Test_MultiError(t *testing.T) {
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+		dbErrTimeout  dbErr = "timeout"
+		dbErrBatch    dbErr = "batch_failed"
+	)
+
+	multi := zerrors.NewMultiError(dbErrBatch)
+	multi.Add(zerrors.Wrap(nil, dbErrTimeout))
+	multi.Add(zerrors.Wrap(nil, dbErrTimeout))
+	require.NoError(t, multi.Err())
+
+	single := zerrors.New(dbErrZeroRows)
+	multi.Add(single)
+	multi.Add(nil)
+	require.Same(t, single, multi.Err())
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			multi.Add(zerrors.New(dbErrTimeout).Tags("retry"))
+		}()
+	}
+	wg.Wait()
+
+	err := multi.Err()
+	zerr, ok := zerrors.AsError[dbErr](err)
+	require.True(t, ok)
+	require.Equal(t, dbErrBatch, zerr.Code())
+	require.True(t, zerrors.HasCode(err, dbErrBatch))
+	require.True(t, zerrors.HasCode(err, dbErrZeroRows))
+	require.True(t, zerrors.HasCode(err, dbErrTimeout))
+	require.Equal(t, []string{"retry"}, zerrors.AllTags(err))
+	require.Equal(t, "batch_failed: zero_rows; timeout; timeout", err.Error())
+}
+
+func Test_WithWrapSites(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	cause := errors.New("no rows")
+
+	err := zerrors.New(domainErrNotFound, zerrors.WithWrapSites())
+	require.Empty(t, err.WrapSites())
+
+	wrapAt := func() { err.WithError(cause) }
+	wrapAt()
+	err.WithErrors(cause, errors.New("closed"))
+
+	sites := err.WrapSites()
+	require.Len(t, sites, 2)
+	require.Equal(t, "Test_WithWrapSites.func1", sites[0].Function)
+	require.Equal(t, "Test_WithWrapSites", sites[1].Function)
+	require.True(t, strings.HasSuffix(sites[1].File, "error_test.go"))
+	require.Greater(t, sites[1].Line, sites[0].Line)
+
+	var logged []any
+	for _, attr := range err.LogValue().Group() {
+		if attr.Key == "wrap_sites" {
+			logged = append(logged, attr.Value.Any())
+		}
+	}
+	require.Equal(t, []any{[]string{sites[0].String(), sites[1].String()}}, logged)
+
+	require.Empty(t, zerrors.New(domainErrNotFound).WithError(cause).WrapSites())
+}
+
+func Test_WithGoroutineID(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	err := zerrors.New(domainErrNotFound, zerrors.WithGoroutineID())
+	require.NotZero(t, err.GoroutineID())
+	require.Equal(t, err.GoroutineID(), zerrors.ToMap(err)["goroutine"])
+
+	other := make(chan *zerrors.Error[domainErr])
+	go func() { other <- zerrors.New(domainErrNotFound, zerrors.WithGoroutineID()) }()
+	require.NotEqual(t, err.GoroutineID(), (<-other).GoroutineID())
+
+	err = zerrors.New(domainErrNotFound)
+	require.Zero(t, err.GoroutineID())
+	require.NotContains(t, zerrors.ToMap(err), "goroutine")
+}
+
+func Test_WithTimestamp(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	before := time.Now()
+	err := zerrors.New(domainErrNotFound, zerrors.WithTimestamp())
+	require.WithinRange(t, err.CreatedAt(), before, time.Now())
+	require.WithinDuration(t, err.CreatedAt(), zerrors.ToMap(err)["created_at"].(time.Time), 0)
+
+	err = zerrors.New(domainErrNotFound)
+	require.Zero(t, err.CreatedAt())
+	require.NotContains(t, zerrors.ToMap(err), "created_at")
+}
+
+func Test_AsError(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	errDB := zerrors.New(dbErrZeroRows)
+	err := fmt.Errorf("handler: %w", zerrors.New(domainErrNotFound).WithError(errDB))
+
+	derr, ok := zerrors.AsError[dbErr](err)
+	require.True(t, ok)
+	require.Same(t, errDB, derr)
+
+	nerr, ok := zerrors.AsError[domainErr](err)
+	require.True(t, ok)
+	require.Equal(t, domainErrNotFound, nerr.Code())
+
+	_, ok = zerrors.AsError[domainErr](errors.New("plain"))
+	require.False(t, ok)
+}
+
+func Test_VerboseError(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	newErr := func() *zerrors.Error[domainErr] {
+		errDB := zerrors.New(dbErrZeroRows).
+			With("query", "SELECT 1").
+			WithSensitive("dsn", "postgres://secret").
+			WithError(errors.New("no rows"))
+		return zerrors.Newf(domainErrNotFound, "user %d", 123).
+			Tags("iam", "authz", "http").
+			With("user_id", 123).
+			With("attempt", 2).
+			WithError(errDB)
+	}
+
+	// Map and set iteration orders vary between runs, the output must not.
+	for range 20 {
+		require.Equal(t,
+			"not_found [tags=authz,http,iam] {attempt=2, user_id=123}: user 123: "+
+				"zero_rows {dsn=[REDACTED], query=SELECT 1}: no rows",
+			newErr().VerboseError())
+	}
+
+	require.Equal(t, "not_found", zerrors.New(domainErrNotFound).VerboseError())
+
+	// Each branch of WithErrors is rendered verbosely.
+	joined := zerrors.New(domainErrNotFound).
+		With("user_id", 123).
+		WithErrors(
+			zerrors.New(dbErrZeroRows).With("query", "SELECT 1").WithError(errors.New("no rows")),
+			errors.New("cache miss"),
+		)
+	require.Equal(t,
+		"not_found {user_id=123}: zero_rows {query=SELECT 1}: no rows; cache miss",
+		joined.VerboseError())
+}
+
+func Test_Inspect(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	err := fmt.Errorf("lookup: %w", zerrors.New(domainErrNotFound).With("user_id", 123))
+
+	var userID any
+	require.True(t, zerrors.Inspect(err, func(zerr *zerrors.Error[domainErr]) {
+		userID, _ = zerr.Get("user_id")
+	}))
+	require.Equal(t, 123, userID)
+
+	require.False(t, zerrors.Inspect(errors.New("plain"), func(*zerrors.Error[domainErr]) {
+		t.Fatal("unexpected call")
+	}))
+}
+
+func Test_Code(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+		domainErrInternal domainErr = "internal"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrNotFound dbErr = "not_found"
+	)
+
+	err := zerrors.New(domainErrInternal).WithError(fmt.Errorf("lookup: %w", zerrors.New(dbErrNotFound)))
+
+	require.ErrorIs(t, err, zerrors.Code(domainErrInternal))
+	require.ErrorIs(t, err, zerrors.Code(domainErrNotFound))
+	require.ErrorIs(t, err, zerrors.Code("not_found"))
+	require.NotErrorIs(t, err, zerrors.Code("zero_rows"))
+	require.NotErrorIs(t, errors.New("not_found"), zerrors.Code(domainErrNotFound))
+
+	require.ErrorIs(t, zerrors.Code(domainErrNotFound), zerrors.New(dbErrNotFound))
+	require.Equal(t, "not_found", zerrors.Code(domainErrNotFound).Error())
+}
+
+func Test_FindByCode(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+		domainErrInternal domainErr = "internal"
+	)
+
+	inner := zerrors.New(domainErrNotFound).With("user_id", 123)
+	err := zerrors.New(domainErrInternal).WithError(fmt.Errorf("lookup: %w", inner))
+
+	found, ok := zerrors.FindByCode(err, domainErrNotFound)
+	require.True(t, ok)
+	require.Same(t, inner, found)
+
+	found, ok = zerrors.FindByCode(err, domainErrInternal)
+	require.True(t, ok)
+	require.Same(t, err, found)
+
+	_, ok = zerrors.FindByCode(errors.New("plain"), domainErrNotFound)
+	require.False(t, ok)
+}
+
+func Test_MarshalText(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	err := zerrors.New(domainErrNotFound).
+		Tags("iam", "a,b").
+		With("user_id", 123).
+		With("query", "a=1|b=2").
+		With("ids", []int{1, 2}).
+		WithSensitive("token", "secret").
+		WithError(errors.New("no rows"))
+
+	text, marshalErr := err.MarshalText()
+	require.NoError(t, marshalErr)
+	require.Equal(t, "not_found|a%2Cb,iam|query=a%3D1%7Cb%3D2,token=[REDACTED],user_id=123", string(text))
+
+	var decoded zerrors.Error[domainErr]
+	require.NoError(t, decoded.UnmarshalText(text))
+	require.Equal(t, domainErrNotFound, decoded.Code())
+	require.ElementsMatch(t, []string{"iam", "a,b"}, decoded.GetTags())
+	require.Equal(t, map[string]any{
+		"query":   "a=1|b=2",
+		"token":   "[REDACTED]",
+		"user_id": "123",
+	}, decoded.GetData())
+
+	text, marshalErr = zerrors.New(domainErrNotFound).MarshalText()
+	require.NoError(t, marshalErr)
+	require.Equal(t, "not_found||", string(text))
+
+	require.Error(t, decoded.UnmarshalText([]byte("not_found")))
+	require.Error(t, decoded.UnmarshalText([]byte("not_found||user_id")))
+}
+
+func Test_WithCode(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrDB          domainErr = "db_error"
+		domainErrUnavailable domainErr = "unavailable"
+	)
+
+	cause := errors.New("connection refused")
+	err := zerrors.Newf(domainErrDB, "query failed").With("host", "db1").Tags("database").WithError(cause)
+	_, line, _, _ := err.Caller()
+
+	require.Same(t, err, err.WithCode(domainErrUnavailable))
+	require.Equal(t, domainErrUnavailable, err.Code())
+	require.Equal(t, "unavailable: query failed: connection refused", err.Error())
+	require.True(t, err.Has("host"))
+	require.True(t, err.HasAllTags("database"))
+	require.ErrorIs(t, err, cause)
+	require.ErrorIs(t, err, zerrors.New(domainErrUnavailable))
+	require.NotErrorIs(t, err, zerrors.New(domainErrDB))
+	_, sameLine, _, _ := err.Caller()
+	require.Equal(t, line, sameLine)
+}
+
+func Test_PropagateTags(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	errDB := zerrors.New(dbErrZeroRows).Tags("database")
+
+	err := zerrors.New(domainErrNotFound).PropagateTags(false).WithError(errDB)
+	require.False(t, err.HasAnyTags("database"))
+	require.True(t, zerrors.AnyTag(err, "database"))
+
+	err = zerrors.New(domainErrNotFound).PropagateTags(false).WithErrors(errDB, context.Canceled)
+	require.Equal(t, []string{zerrors.TagCanceled}, err.GetTags())
+
+	err = zerrors.New(domainErrNotFound).PropagateTags(false).PropagateTags(true).WithError(errDB)
+	require.True(t, err.HasAllTags("database"))
+}
+
+func Test_Log(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "stack" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	err := zerrors.New(domainErrNotFound).WithSeverity(zerrors.SeverityWarn)
+	require.Equal(t, zerrors.SeverityWarn, err.Severity())
+	err.Log(t.Context(), logger)
+	require.JSONEq(t,
+		`{"level":"WARN","msg":"not_found","error":{"code":"not_found","error":"not_found"}}`,
+		buf.String())
+
+	// The severity of an inner error applies when the outer one has none.
+	buf.Reset()
+	zerrors.Log(t.Context(), logger, fmt.Errorf("batch: %w", err))
+	require.Contains(t, buf.String(), `"level":"WARN"`)
+
+	buf.Reset()
+	zerrors.Log(t.Context(), logger, zerrors.New(domainErrNotFound).WithSeverity(zerrors.SeverityCritical))
+	require.Contains(t, buf.String(), `"level":"ERROR"`)
+
+	buf.Reset()
+	zerrors.Log(t.Context(), logger, errors.New("plain"))
+	require.JSONEq(t, `{"level":"ERROR","msg":"plain","error":"plain"}`, buf.String())
+
+	buf.Reset()
+	zerrors.Log(t.Context(), logger, nil)
+	zerrors.Log(t.Context(), logger, zerrors.Wrap(nil, domainErrNotFound))
+	(*zerrors.Error[domainErr])(nil).Log(t.Context(), logger)
+	require.Empty(t, buf.String())
+}
+
+func Test_ContextErrors(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrAborted domainErr = "aborted"
+	)
+
+	canceled := zerrors.Wrap(fmt.Errorf("query: %w", context.Canceled), domainErrAborted)
+	require.True(t, canceled.HasAllTags(zerrors.TagCanceled))
+	require.False(t, canceled.Timeout())
+	require.False(t, canceled.Temporary())
+
+	timeout := zerrors.Wrap(context.DeadlineExceeded, domainErrAborted)
+	require.True(t, timeout.HasAllTags(zerrors.TagTimeout))
+	require.False(t, timeout.HasAnyTags(zerrors.TagCanceled))
+	require.True(t, timeout.Timeout())
+	require.True(t, timeout.Temporary())
+
+	suppressed := zerrors.New(domainErrAborted, zerrors.WithoutContextTags()).WithError(context.DeadlineExceeded)
+	require.Empty(t, suppressed.GetTags())
+	require.False(t, suppressed.Timeout())
+	require.ErrorIs(t, suppressed, context.DeadlineExceeded)
+
+	require.Empty(t, zerrors.Wrap(errors.New("plain"), domainErrAborted).GetTags())
+}
+
+func Test_TrimFilePath(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	file, _, _, ok := zerrors.New(domainErrNotFound).Caller()
+	require.True(t, ok)
+	require.Equal(t, "error_test.go", file)
+
+	_, thisFile, _, _ := runtime.Caller(0)
+	moduleDir := filepath.Dir(thisFile)
+	zerrors.SetTrimPrefix(filepath.Dir(moduleDir) + "/")
+	t.Cleanup(func() { zerrors.SetTrimPrefix("") })
+
+	file, _, _, ok = zerrors.New(domainErrNotFound).Caller()
+	require.True(t, ok)
+	require.Equal(t, filepath.Base(moduleDir)+"/error_test.go", file)
+}
+
+func Test_WithStack(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	err := zerrors.New(domainErrNotFound, zerrors.WithoutStack())
+	_, _, _, ok := err.Caller()
+	require.False(t, ok)
+
+	attach := func() { err.WithStack() }
+	attach()
+	_, _, function, ok := err.Caller()
+	require.True(t, ok)
+	require.Equal(t, "Test_WithStack.func1", function)
+	require.Contains(t, zerrors.ToMap(err), "stack")
+}
+
+func Test_WithSlogSourceStack(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	err := zerrors.New(domainErrNotFound, zerrors.WithSlogSourceStack())
+
+	sources, ok := zerrors.ToMap(err)["stack"].([]*slog.Source)
+	require.True(t, ok)
+	require.NotEmpty(t, sources)
+	require.Equal(t, "github.com/DeluxeOwl/zerrors_test.Test_WithSlogSourceStack", sources[0].Function)
+	file, line, _, _ := err.Caller()
+	require.Equal(t, file, sources[0].File)
+	require.Equal(t, line, sources[0].Line)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+	require.Contains(t, buf.String(), `"stack":[{"function":"github.com/DeluxeOwl/zerrors_test.Test_WithSlogSourceStack"`)
+}
+
+func Test_HasStack(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	require.True(t, zerrors.New(domainErrNotFound).HasStack())
+	require.False(t, zerrors.New(domainErrNotFound, zerrors.WithoutStack()).HasStack())
+	require.True(t, zerrors.New(domainErrNotFound, zerrors.WithoutStack()).WithStack().HasStack())
+	require.False(t, zerrors.NewWithStack(domainErrNotFound, nil).HasStack())
+	require.False(t, zerrors.NewMatcher(domainErrNotFound).HasStack())
+
+	var err *zerrors.Error[domainErr]
+	require.False(t, err.HasStack())
+}
+
+func Test_NewWithStack(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrPanic domainErr = "panic"
+	)
+
+	var err *zerrors.Error[domainErr]
+	func() {
+		defer func() {
+			if recover() != nil {
+				pcs := make([]uintptr, 32)
+				err = zerrors.NewWithStack(domainErrPanic, pcs[:runtime.Callers(0, pcs)])
+			}
+		}()
+		panicAt()
+	}()
+
+	require.NotNil(t, err)
+	var functions []string
+	for _, frame := range err.StackFrames() {
+		functions = append(functions, frame.Function)
+	}
+	require.Contains(t, functions, "panicAt")
+
+	err = zerrors.NewWithStack(domainErrPanic, nil)
+	require.Empty(t, err.StackFrames())
+	require.NotContains(t, zerrors.ToMap(err), "stack")
+
+	err = zerrors.NewWithStack(domainErrPanic, []uintptr{1, 2})
+	require.Empty(t, err.StackFrames())
+}
+
+func panicAt() {
+	panic("boom")
+}
+
+func Test_Fingerprint(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+		domainErrInternal domainErr = "internal"
+	)
+
+	newErr := func(code domainErr, userID int) *zerrors.Error[domainErr] {
+		return zerrors.New(code).With("user_id", userID).Tags(fmt.Sprint("user-", userID))
+	}
+
+	a, b := newErr(domainErrNotFound, 1), newErr(domainErrNotFound, 2)
+	require.Len(t, a.Fingerprint(), 64)
+	require.Equal(t, a.Fingerprint(), b.Fingerprint())
+	require.Equal(t, a.Fingerprint(zerrors.WithFingerprintLines()), b.Fingerprint(zerrors.WithFingerprintLines()))
+	require.NotEqual(t, a.Fingerprint(), newErr(domainErrInternal, 1).Fingerprint())
+
+	// Another line of the same function only differs when lines are included.
+	c := zerrors.New(domainErrNotFound)
+	d := zerrors.New(domainErrNotFound)
+	require.Equal(t, c.Fingerprint(), d.Fingerprint())
+	require.NotEqual(t, c.Fingerprint(zerrors.WithFingerprintLines()), d.Fingerprint(zerrors.WithFingerprintLines()))
+
+	// Only the top frame differs from a, so a single-frame fingerprint still does.
+	require.NotEqual(t, a.Fingerprint(zerrors.WithFingerprintFrames(1)), c.Fingerprint(zerrors.WithFingerprintFrames(1)))
+
+	// The format is stable: the SHA-256 of the code alone when there is no stack.
+	require.Equal(t,
+		"d1c90f940d4431d00d05cc78d45579fb978e74458608777b34b54b8acd11459c",
+		zerrors.New(domainErrNotFound, zerrors.WithoutStack()).Fingerprint())
+}
+
+func Test_StackFrames(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	frames := zerrors.New(domainErrNotFound).StackFrames()
+	require.NotEmpty(t, frames)
+	require.Equal(t, "Test_StackFrames", frames[0].Function)
+	require.Equal(t, "error_test.go", frames[0].File)
+
+	require.Nil(t, zerrors.New(domainErrNotFound, zerrors.WithoutStack()).StackFrames())
+}
+
+func Benchmark_WithError(b *testing.B) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+		domainErrInternal domainErr = "internal"
+	)
+
+	chain := zerrors.New(domainErrNotFound).WithError(zerrors.New(domainErrNotFound).WithError(errors.New("no rows")))
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = zerrors.New(domainErrInternal).WithError(chain)
+	}
+}
+
+func Benchmark_New(b *testing.B) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	b.Run("capture", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = zerrors.New(domainErrNotFound)
+		}
+	})
+
+	// Tags are allocated on first use, so tagless errors do not pay for the set.
+	b.Run("capture_with_tags", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = zerrors.New(domainErrNotFound).Tags("iam")
+		}
+	})
+
+	// Resolving the frames is the cost every error paid when capture was eager.
+	b.Run("capture_and_resolve", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = zerrors.New(domainErrNotFound).StackFrames()
+		}
+	})
+}
+
+func Test_WithErrorf(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	cause := zerrors.New(dbErrZeroRows).Tags("database")
+	err := zerrors.New(domainErrNotFound).WithErrorf(cause, "user %d", 123)
+
+	require.Equal(t, "not_found: user 123: zero_rows", err.Error())
+	require.ErrorIs(t, err, cause)
+	require.Same(t, cause, errors.Unwrap(err))
+	require.True(t, err.HasAllTags("database"))
+}
+
+func Test_SetLogKeys(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	zerrors.SetLogKeys(zerrors.LogKeys{
+		Code:  "error_code",
+		Error: "error_message",
+		Data:  "context",
+	})
+	t.Cleanup(func() { zerrors.SetLogKeys(zerrors.LogKeys{}) })
+
+	err := zerrors.New(domainErrNotFound, zerrors.WithoutStack()).
+		With("user_id", 123).
+		Tags("iam").
+		WithError(errors.New("no rows"))
+
+	require.Equal(t, map[string]any{
+		"error_code":    "not_found",
+		"error_message": "not_found: no rows",
+		"context":       map[string]any{"user_id": int64(123)},
+		"tags":          []string{"iam"},
+		"wrapped":       "no rows",
+	}, zerrors.ToMap(err))
+	require.Equal(t, map[string]any{"error_message": "plain"}, zerrors.ToMap(errors.New("plain")))
+}
+
+func Test_SetInlineData(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	zerrors.SetInlineData(true)
+	t.Cleanup(func() { zerrors.SetInlineData(false) })
+
+	err := zerrors.New(domainErrNotFound, zerrors.WithoutStack()).
+		With("user_id", 123).
+		WithSensitive("token", "secret")
+
+	require.Equal(t, map[string]any{
+		"code":    "not_found",
+		"error":   "not_found",
+		"user_id": int64(123),
+		"token":   "[REDACTED]",
+	}, zerrors.ToMap(err))
+}
+
+type driverError struct {
+	code   string
+	detail string
+}
+
+func (e *driverError) Error() string { return e.code + ": " + e.detail }
+
+func Test_SetWrappedRenderer(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	zerrors.SetWrappedRenderer(func(err error) slog.Value {
+		var driverErr *driverError
+		if !errors.As(err, &driverErr) {
+			return slog.Value{}
+		}
+		return slog.GroupValue(slog.String("code", driverErr.code), slog.String("detail", driverErr.detail))
+	})
+	t.Cleanup(func() { zerrors.SetWrappedRenderer(nil) })
+
+	err := zerrors.New(domainErrNotFound, zerrors.WithoutStack()).
+		WithError(&driverError{code: "23505", detail: "duplicate key"})
+	require.Equal(t, map[string]any{"code": "23505", "detail": "duplicate key"}, zerrors.ToMap(err)["wrapped"])
+
+	err = zerrors.New(domainErrNotFound, zerrors.WithoutStack()).WithError(errors.New("no rows"))
+	require.Equal(t, "no rows", zerrors.ToMap(err)["wrapped"])
+}
+
+func Test_SetMaxDataEntries(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	zerrors.SetMaxDataEntries(2)
+	t.Cleanup(func() { zerrors.SetMaxDataEntries(0) })
+
+	err := zerrors.New(domainErrNotFound, zerrors.WithoutStack()).
+		With("user_id", 123).
+		With("attempt", 1).
+		With("payload", make([]byte, 1<<20)).
+		WithSensitive("token", "secret").
+		With("attempt", 2)
+
+	require.Equal(t, []string{"attempt", "user_id"}, err.Keys())
+	require.Equal(t, map[string]any{
+		"code":           "not_found",
+		"error":          "not_found",
+		"data":           map[string]any{"user_id": int64(123), "attempt": int64(2)},
+		"data_truncated": true,
+	}, zerrors.ToMap(err))
+
+	// The cap also applies to data attached at creation and by Merge.
+	zerrors.SetMaxDataEntries(1)
+	initial := map[string]any{"x": 1, "y": 2, "z": 3}
+
+	err = zerrors.New(domainErrNotFound, zerrors.WithInitialData(initial))
+	require.Equal(t, []string{"x"}, err.Keys())
+	require.Equal(t, true, zerrors.ToMap(err)["data_truncated"])
+
+	err = zerrors.NewFactory[domainErr](nil, initial).New(domainErrNotFound)
+	require.Equal(t, []string{"x"}, err.Keys())
+	require.Equal(t, true, zerrors.ToMap(err)["data_truncated"])
+
+	merged := zerrors.New(domainErrNotFound).With("a", 1).Merge(zerrors.New(domainErrNotFound).With("b", 2))
+	require.Equal(t, []string{"a"}, merged.Keys())
+	require.Equal(t, true, zerrors.ToMap(merged)["data_truncated"])
+
+	zerrors.SetMaxDataEntries(0)
+	require.NotContains(t, zerrors.ToMap(zerrors.New(domainErrNotFound).With("user_id", 123)), "data_truncated")
+}
+
+func Test_SetOTelSemConv(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	zerrors.SetOTelSemConv(true)
+	t.Cleanup(func() { zerrors.SetOTelSemConv(false) })
+
+	m := zerrors.ToMap(zerrors.New(domainErrNotFound).With("user_id", 123))
+	require.Equal(t, "not_found", m["exception.type"])
+	require.Equal(t, "not_found", m["exception.message"])
+	require.Contains(t, m, "exception.stacktrace")
+	require.Contains(t, m, "data")
+	require.NotContains(t, m, "code")
+	require.NotContains(t, m, "stack")
+
+	zerrors.SetOTelSemConv(false)
+	require.Contains(t, zerrors.ToMap(zerrors.New(domainErrNotFound)), "code")
+}
+
+func Test_SetDataValueHook(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	var hooked []string
+	zerrors.SetDataValueHook(func(key string, v any) any {
+		hooked = append(hooked, key)
+		if key == "elapsed" {
+			return v.(time.Duration).String()
+		}
+		return v
+	})
+	t.Cleanup(func() { zerrors.SetDataValueHook(nil) })
+
+	err := zerrors.New(domainErrNotFound, zerrors.WithoutStack()).
+		With("elapsed", 1500*time.Millisecond).
+		With("user_id", 123).
+		WithSensitive("token", "secret")
+
+	require.Equal(t, map[string]any{
+		"elapsed": "1.5s",
+		"user_id": int64(123),
+		"token":   "[REDACTED]",
+	}, zerrors.ToMap(err)["data"])
+	require.ElementsMatch(t, []string{"elapsed", "user_id"}, hooked)
+
+	elapsed, _ := err.Get("elapsed")
+	require.Equal(t, 1500*time.Millisecond, elapsed)
+}
+
+func Test_SetMaxLogDepth(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrLink domainErr = "link"
+	)
+
+	err := zerrors.New(domainErrLink, zerrors.WithoutStack())
+	for range 999 {
+		err = zerrors.New(domainErrLink, zerrors.WithoutStack()).WithError(err)
 	}
+
+	depth := func(m map[string]any) int {
+		n := 1
+		for {
+			wrapped, ok := m["wrapped"].(map[string]any)
+			if !ok {
+				require.Equal(t, fmt.Sprintf("... truncated (%d more)", 1000-n), m["wrapped"])
+				return n
+			}
+			m = wrapped
+			n++
+		}
+	}
+
+	require.Equal(t, 32, depth(zerrors.ToMap(err)))
+
+	// Only the outermost level renders the message of the whole chain.
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "err", err)
+	require.Less(t, buf.Len(), len(err.Error())+4096)
+
+	zerrors.SetMaxLogDepth(3)
+	t.Cleanup(func() { zerrors.SetMaxLogDepth(0) })
+
+	require.Equal(t, 3, depth(zerrors.ToMap(err)))
+}
+
+func Test_Coerce(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+		domainErrInternal domainErr = "internal"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	require.Nil(t, zerrors.Coerce(nil, domainErrInternal))
+
+	errNotFound := zerrors.New(domainErrNotFound)
+	require.Same(t, errNotFound, zerrors.Coerce(errNotFound, domainErrInternal))
+
+	plain := errors.New("plain")
+	coerced := zerrors.Coerce(plain, domainErrInternal)
+	require.Equal(t, domainErrInternal, coerced.Code())
+	require.ErrorIs(t, coerced, plain)
+	_, _, function, _ := coerced.Caller()
+	require.Equal(t, "Test_Coerce", function)
+
+	errDB := zerrors.New(dbErrZeroRows)
+	coerced = zerrors.Coerce(errDB, domainErrInternal)
+	require.Equal(t, domainErrInternal, coerced.Code())
+	require.True(t, zerrors.HasCode(coerced, dbErrZeroRows))
+}
+
+func Test_Annotate(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+		domainErrInternal domainErr = "internal"
+	)
+
+	inner := zerrors.New(domainErrNotFound)
+	err := zerrors.Annotate(fmt.Errorf("lookup: %w", inner), domainErrInternal).With("user_id", 123)
+	require.Equal(t, domainErrNotFound, err.Code())
+	require.ErrorIs(t, err, inner)
+	require.True(t, err.Has("user_id"))
+	_, _, function, _ := err.Caller()
+	require.Equal(t, "Test_Annotate", function)
+
+	err = zerrors.Annotate(errors.New("plain"), domainErrInternal)
+	require.Equal(t, domainErrInternal, err.Code())
+	require.Equal(t, "internal: plain", err.Error())
+
+	require.Nil(t, zerrors.Annotate(nil, domainErrInternal))
+}
+
+func Test_NilReceiver(t *testing.T) {
+	type domainErr string
+
+	var err *zerrors.Error[domainErr]
+
+	require.Empty(t, err.Error())
+	require.Empty(t, err.Code())
+	require.Empty(t, err.CodeString())
+	require.Nil(t, err.GetTags())
+	require.False(t, err.HasTags("iam"))
+	require.False(t, err.HasAllTags("iam"))
+	require.False(t, err.HasAnyTags("iam"))
+	_, ok := err.Get("user_id")
+	require.False(t, ok)
+	require.False(t, err.Has("user_id"))
+	require.NoError(t, err.Unwrap())
+	require.Empty(t, err.LogValue().Group())
+	require.False(t, errors.Is(err, zerrors.New(domainErr("not_found"))))
+
+	// A nil Error nested in another one, as returned by Wrap(nil, code), is not wrapped.
+	type dbErr string
+
+	nested := zerrors.New(domainErr("not_found"), zerrors.WithoutStack()).
+		WithError(zerrors.Wrap(nil, dbErr("zero_rows")))
+	require.Equal(t, "not_found", nested.Error())
+	require.NoError(t, nested.Unwrap())
+	require.Equal(t, map[string]any{"code": "not_found", "error": "not_found"}, zerrors.ToMap(nested))
+	require.Equal(t, "not_found", nested.VerboseError())
+	require.Equal(t, []zerrors.NodeSnapshot{{Code: "not_found"}}, zerrors.ChainSnapshot(nested))
+
+	cause := errors.New("closed")
+	nested = zerrors.New(domainErr("not_found")).WithErrors(zerrors.Wrap(nil, dbErr("zero_rows")), cause)
+	require.Equal(t, "not_found: closed", nested.Error())
+	require.Equal(t, "not_found: closed", nested.WithErrorf(zerrors.Wrap(nil, dbErr("zero_rows")), "closed").Error())
+
+	var asErr error = err
+	var pathErr *fs.PathError
+	require.False(t, errors.As(asErr, &pathErr))
+	_, ok = zerrors.AsError[dbErr](asErr)
+	require.False(t, ok)
+
+	require.NotPanics(t, func() { zerrors.ChainSnapshot(err) })
+	require.NotPanics(t, func() { zerrors.ToMap(err) })
+}
+
+func Test_Merge(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrInvalid  domainErr = "invalid"
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	causeA := errors.New("missing name")
+	causeB := errors.New("missing email")
+
+	validation := zerrors.New(domainErrInvalid).
+		With("field_count", 2).
+		With("source", "validation").
+		Tags("validation").
+		WithError(causeA)
+	enrichment := zerrors.New(domainErrNotFound).
+		With("source", "enrichment").
+		WithSensitive("email", "jane@example.com").
+		Tags("enriched").
+		WithError(causeB)
+
+	merged := validation.Merge(enrichment)
+
+	require.Equal(t, domainErrInvalid, merged.Code())
+	require.ElementsMatch(t, []string{"validation", "enriched"}, merged.GetTags())
+	require.Equal(t, map[string]any{
+		"field_count": 2,
+		"source":      "validation",
+		"email":       "jane@example.com",
+	}, merged.GetData())
+	require.Equal(t, map[string]any{
+		"field_count": 2,
+		"source":      "validation",
+		"email":       "[REDACTED]",
+	}, zerrors.FlattenData(merged))
+	require.ErrorIs(t, merged, causeA)
+	require.ErrorIs(t, merged, causeB)
+	require.Equal(t, "invalid: missing name; missing email", merged.Error())
+	require.Equal(t, validation.StackTrace(), merged.StackTrace())
+
+	// The inputs are left untouched.
+	require.ElementsMatch(t, []string{"validation"}, validation.GetTags())
+	require.False(t, validation.Has("email"))
+	require.Same(t, causeA, validation.Unwrap())
+
+	require.True(t, validation.Merge(nil).Equal(validation))
+}
+
+func Test_Builder(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	cause := errors.New("no rows")
+
+	err, buildErr := zerrors.NewBuilder[domainErr]().
+		Code(domainErrNotFound).
+		Tag("iam").
+		Data("user_id", 123).
+		Wrap(cause).
+		Message("user %d", 123).
+		Build()
+	require.NoError(t, buildErr)
+	require.Equal(t, "not_found: user 123: no rows", err.Error())
+	require.True(t, err.HasAllTags("iam"))
+	require.True(t, err.Has("user_id"))
+	require.ErrorIs(t, err, cause)
+	_, _, function, _ := err.Caller()
+	require.Equal(t, "Test_Builder", function)
+
+	// Validation problems are not application errors, so they skip the creation hook.
+	var created []string
+	zerrors.SetOnCreate(func(code string, _ []string) { created = append(created, code) })
+	t.Cleanup(func() { zerrors.SetOnCreate(nil) })
+
+	err, buildErr = zerrors.NewBuilder[domainErr]().
+		Data("user_id", 1).
+		Data("user_id", 2).
+		Data("", 3).
+		Build()
+	require.Nil(t, err)
+	require.Empty(t, created)
+	require.ErrorIs(t, buildErr, zerrors.NewMatcher(zerrors.BuilderErrEmptyCode))
+	require.ErrorIs(t, buildErr, zerrors.NewMatcher(zerrors.BuilderErrEmptyKey))
+	require.ErrorIs(t, buildErr, zerrors.NewMatcher(zerrors.BuilderErrDuplicateKey))
 }
@@ -0,0 +1,28 @@
+package zerrors
+
+import (
+	"maps"
+	"slices"
+)
+
+// Factory creates Errors pre-populated with a set of tags and data.
+type Factory[T ~string] struct {
+	tags []string
+	data map[string]any
+}
+
+// NewFactory creates a Factory whose errors carry the given tags and data.
+// The presets are copied, so later changes to the arguments have no effect.
+func NewFactory[T ~string](presetTags []string, presetData map[string]any) *Factory[T] {
+	return &Factory[T]{
+		tags: slices.Clone(presetTags),
+		data: maps.Clone(presetData),
+	}
+}
+
+// New creates a new Error with the factory presets, configured by the given options.
+// The stack is captured at the call site. Each error gets its own copy of the presets.
+func (f *Factory[T]) New(code T, opts ...Option) *Error[T] {
+	presets := []Option{WithInitialTags(f.tags...), WithInitialData(f.data)}
+	return newWithSkip(code, 1, append(presets, opts...)...).created()
+}
@@ -0,0 +1,65 @@
+package zerrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strconv"
+)
+
+// defaultFingerprintFrames is the number of stack frames a fingerprint covers by default.
+const defaultFingerprintFrames = 5
+
+// FingerprintOption configures Fingerprint.
+type FingerprintOption func(*fingerprintConfig)
+
+type fingerprintConfig struct {
+	frames int
+	lines  bool
+}
+
+// WithFingerprintFrames sets how many of the top stack frames feed the fingerprint.
+// Values of 0 or less keep the default of 5.
+func WithFingerprintFrames(n int) FingerprintOption {
+	return func(c *fingerprintConfig) {
+		if n > 0 {
+			c.frames = n
+		}
+	}
+}
+
+// WithFingerprintLines includes line numbers in the fingerprint, so that errors
+// raised at different lines of the same function are grouped apart.
+func WithFingerprintLines() FingerprintOption {
+	return func(c *fingerprintConfig) {
+		c.lines = true
+	}
+}
+
+// Fingerprint returns a hash grouping the occurrences of the same logical error,
+// e.g. for error trackers. It covers the code and the top frames of the stack,
+// excluding data and tags since they vary per occurrence, and line numbers
+// unless WithFingerprintLines is given, so that unrelated edits do not regroup errors.
+//
+// The fingerprint is the lowercase hex SHA-256 of the code followed by, for each
+// frame, its full function name, the base name of its file and, if enabled, its line,
+// each terminated by a newline. This format is kept stable across versions.
+func (e *Error[T]) Fingerprint(opts ...FingerprintOption) string {
+	cfg := fingerprintConfig{frames: defaultFingerprintFrames}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(string(e.code) + "\n"))
+	if e.stack != nil {
+		frames := e.stack.resolved()
+		for _, frame := range frames[:min(cfg.frames, len(frames))] {
+			h.Write([]byte(frame.fullFunction + "\n" + path.Base(frame.file) + "\n"))
+			if cfg.lines {
+				h.Write([]byte(strconv.Itoa(frame.line) + "\n"))
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
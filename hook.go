@@ -0,0 +1,25 @@
+package zerrors
+
+import "sync/atomic"
+
+// onCreate holds the hook registered with SetOnCreate.
+var onCreate atomic.Pointer[func(code string, tags []string)]
+
+// SetOnCreate registers fn to be called every time an Error is constructed,
+// e.g. to count errors by code. Passing nil removes the hook.
+// fn may be called from many goroutines at once.
+func SetOnCreate(fn func(code string, tags []string)) {
+	if fn == nil {
+		onCreate.Store(nil)
+		return
+	}
+	onCreate.Store(&fn)
+}
+
+// created invokes the creation hook, if any, and returns e.
+func (e *Error[T]) created() *Error[T] {
+	if fn := onCreate.Load(); fn != nil {
+		(*fn)(e.CodeString(), e.GetTags())
+	}
+	return e
+}
@@ -0,0 +1,42 @@
+package zerrors
+
+// Key is a typed data key, created with NewKey, giving compile-time typing to
+// the values set with WithKey and read with GetKey. Values are stored under the
+// name of the key, like those set with With, so they are logged the same way.
+type Key[V any] struct {
+	name string
+}
+
+// NewKey creates a Key storing values of type V under name.
+// Prefixing the name, e.g. with the package name, avoids collisions between packages.
+func NewKey[V any](name string) Key[V] {
+	return Key[V]{name: name}
+}
+
+// Name returns the name the values of the key are stored under.
+func (k Key[V]) Name() string {
+	return k.name
+}
+
+// WithKey attaches v to e under key. It is a function since methods cannot have type parameters.
+func WithKey[T ~string, V any](e *Error[T], key Key[V], v V) *Error[T] {
+	return e.With(key.name, v)
+}
+
+// GetKey returns the value stored under key by the first Error in the chain of err
+// holding a value of type V under its name.
+func GetKey[V any](err error, key Key[V]) (V, bool) {
+	for e := range Iter(err) {
+		g, ok := e.(chainNode)
+		if !ok {
+			continue
+		}
+		if v, ok := g.Get(key.name); ok {
+			if typed, ok := v.(V); ok {
+				return typed, true
+			}
+		}
+	}
+	var zero V
+	return zero, false
+}
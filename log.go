@@ -0,0 +1,155 @@
+package zerrors
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// LogKeys holds the attribute names emitted by LogValue and ToMap.
+// Empty fields keep their default name.
+type LogKeys struct {
+	Code          string // default "code"
+	Error         string // default "error"
+	Data          string // default "data"
+	DataTruncated string // default "data_truncated"
+	Tags          string // default "tags"
+	Wrapped       string // default "wrapped"
+	WrapSites     string // default "wrap_sites"
+	Goroutine     string // default "goroutine"
+	CreatedAt     string // default "created_at"
+	Stack         string // default "stack"
+}
+
+var defaultLogKeys = LogKeys{
+	Code:          "code",
+	Error:         "error",
+	Data:          "data",
+	DataTruncated: "data_truncated",
+	Tags:          "tags",
+	Wrapped:       "wrapped",
+	WrapSites:     "wrap_sites",
+	Goroutine:     "goroutine",
+	CreatedAt:     "created_at",
+	Stack:         "stack",
+}
+
+var logKeys atomic.Pointer[LogKeys]
+
+// SetLogKeys remaps the attribute names emitted by LogValue and ToMap.
+// The setting is process-global and meant to be set once at startup.
+func SetLogKeys(keys LogKeys) {
+	orDefault := func(key *string, def string) {
+		if *key == "" {
+			*key = def
+		}
+	}
+	orDefault(&keys.Code, defaultLogKeys.Code)
+	orDefault(&keys.Error, defaultLogKeys.Error)
+	orDefault(&keys.Data, defaultLogKeys.Data)
+	orDefault(&keys.DataTruncated, defaultLogKeys.DataTruncated)
+	orDefault(&keys.Tags, defaultLogKeys.Tags)
+	orDefault(&keys.Wrapped, defaultLogKeys.Wrapped)
+	orDefault(&keys.WrapSites, defaultLogKeys.WrapSites)
+	orDefault(&keys.Goroutine, defaultLogKeys.Goroutine)
+	orDefault(&keys.CreatedAt, defaultLogKeys.CreatedAt)
+	orDefault(&keys.Stack, defaultLogKeys.Stack)
+	logKeys.Store(&keys)
+}
+
+var otelSemConv atomic.Bool
+
+// SetOTelSemConv switches the code, message and stack attributes emitted by LogValue
+// and ToMap to the OpenTelemetry exception semantic conventions: "exception.type",
+// "exception.message" and "exception.stacktrace". It takes precedence over the names
+// set with SetLogKeys for these attributes. Disabled by default.
+func SetOTelSemConv(enabled bool) {
+	otelSemConv.Store(enabled)
+}
+
+// currentLogKeys returns the attribute names in use.
+func currentLogKeys() LogKeys {
+	keys := defaultLogKeys
+	if custom := logKeys.Load(); custom != nil {
+		keys = *custom
+	}
+	if otelSemConv.Load() {
+		keys.Code = "exception.type"
+		keys.Error = "exception.message"
+		keys.Stack = "exception.stacktrace"
+	}
+	return keys
+}
+
+// defaultMaxLogDepth is the number of chain levels rendered by LogValue by default.
+const defaultMaxLogDepth = 32
+
+var maxLogDepth atomic.Int64
+
+// SetMaxLogDepth limits how many levels of a wrapped chain LogValue renders,
+// replacing the rest with a "... truncated (N more)" marker, so pathological
+// chains cannot produce huge log lines. A value of 0 or less restores the default of 32.
+func SetMaxLogDepth(n int) {
+	maxLogDepth.Store(int64(max(n, 0)))
+}
+
+func currentMaxLogDepth() int {
+	if n := int(maxLogDepth.Load()); n > 0 {
+		return n
+	}
+	return defaultMaxLogDepth
+}
+
+var inlineData atomic.Bool
+
+// SetInlineData controls whether LogValue emits the data of an error as sibling
+// attributes of its code and message, instead of nested under the "data" group.
+// Inlined keys may collide with the other attribute names, see SetLogKeys.
+// Disabled by default.
+func SetInlineData(inline bool) {
+	inlineData.Store(inline)
+}
+
+// wrappedRenderer holds the renderer registered with SetWrappedRenderer.
+var wrappedRenderer atomic.Pointer[func(error) slog.Value]
+
+// SetWrappedRenderer registers fn to render the wrapped errors that are not
+// Errors of this package in LogValue, e.g. to emit the fields of a driver error
+// as a group. fn returns the zero slog.Value to fall back to the default, which
+// uses the error's LogValue if it implements slog.LogValuer and its message otherwise.
+// Passing nil removes the renderer.
+func SetWrappedRenderer(fn func(error) slog.Value) {
+	if fn == nil {
+		wrappedRenderer.Store(nil)
+		return
+	}
+	wrappedRenderer.Store(&fn)
+}
+
+// maxDataEntries caps the number of data entries of an Error, 0 means unlimited.
+var maxDataEntries atomic.Int64
+
+// SetMaxDataEntries caps how many data entries an Error holds, whether attached
+// with With, WithSensitive, WithInitialData, a Factory or Merge. Once the cap is
+// reached, values under new keys are dropped and the error is logged with
+// "data_truncated" set to true, while existing keys can still be overwritten.
+// Initial and merged data is admitted in key order.
+// A value of 0 or less removes the cap, which is the default.
+func SetMaxDataEntries(n int) {
+	maxDataEntries.Store(int64(max(n, 0)))
+}
+
+// dataValueHook holds the hook registered with SetDataValueHook.
+var dataValueHook atomic.Pointer[func(key string, v any) any]
+
+// SetDataValueHook registers fn to transform each data value before it leaves an
+// Error, in LogValue, ToMap, VerboseError, MarshalText and FlattenData, e.g. to
+// format durations or expand structs. It receives the key of the value and returns
+// its replacement. Sensitive values are redacted without being passed to fn.
+// The attached data itself is unchanged. Passing nil removes the hook.
+func SetDataValueHook(fn func(key string, v any) any) {
+	if fn == nil {
+		dataValueHook.Store(nil)
+		return
+	}
+	dataValueHook.Store(&fn)
+}
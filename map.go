@@ -0,0 +1,39 @@
+package zerrors
+
+import "log/slog"
+
+// ToMap returns the same structured view of err as LogValue, as a plain nested map
+// that loggers other than slog can consume. Wrapped errors of this package become
+// nested maps, other wrapped errors become their message.
+// A plain error is returned as a map holding only its message.
+func ToMap(err error) map[string]any {
+	if err == nil {
+		return nil
+	}
+	if logValuer, ok := err.(slog.LogValuer); ok {
+		if v := logValuer.LogValue().Resolve(); v.Kind() == slog.KindGroup {
+			return groupToMap(v.Group())
+		}
+	}
+	return map[string]any{currentLogKeys().Error: err.Error()}
+}
+
+func groupToMap(attrs []slog.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, attr := range attrs {
+		v := attr.Value.Resolve()
+		if v.Kind() != slog.KindGroup {
+			m[attr.Key] = v.Any()
+			continue
+		}
+		// Inline groups with an empty key, the same way slog handlers do.
+		if attr.Key == "" {
+			for k, inner := range groupToMap(v.Group()) {
+				m[k] = inner
+			}
+			continue
+		}
+		m[attr.Key] = groupToMap(v.Group())
+	}
+	return m
+}
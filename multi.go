@@ -0,0 +1,43 @@
+package zerrors
+
+import "sync"
+
+// MultiError collects the errors of concurrent tasks, keeping all of them
+// rather than only the first. It is safe for concurrent use.
+type MultiError[T ~string] struct {
+	code T
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewMultiError creates an empty MultiError aggregating errors under code.
+func NewMultiError[T ~string](code T) *MultiError[T] {
+	return &MultiError[T]{code: code}
+}
+
+// Add collects err. A nil err, including a nil *Error, is ignored.
+func (m *MultiError[T]) Add(err error) {
+	if orNil(err) == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errs = append(m.errs, err)
+}
+
+// Err returns nil if no error was collected, and the single error if only one was.
+// Otherwise it returns an Error with the aggregate code wrapping all of them,
+// in the order they were added, so that chain helpers such as HasCode and
+// AllTags see every one.
+func (m *MultiError[T]) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch len(m.errs) {
+	case 0:
+		return nil
+	case 1:
+		return m.errs[0]
+	default:
+		return newWithSkip(m.code, 1).WithErrors(m.errs...).created()
+	}
+}
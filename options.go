@@ -0,0 +1,123 @@
+package zerrors
+
+import "maps"
+
+// Option configures an Error when it is created with New.
+type Option func(*config)
+
+type config struct {
+	stackDepth int
+	noStack    bool
+	tags       []string
+	data       map[string]any
+	render     renderConfig
+
+	recordWrapSites bool
+	noContextTags   bool
+	goroutineID     bool
+	timestamp       bool
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{
+		stackDepth: defaultStackDepth,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithStackDepth sets the maximum number of frames captured in the stack.
+// Values of 0 or less keep the default depth.
+func WithStackDepth(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.stackDepth = n
+		}
+	}
+}
+
+// WithoutStack skips stack capture entirely.
+func WithoutStack() Option {
+	return func(c *config) {
+		c.noStack = true
+	}
+}
+
+// WithInitialTags adds tags to the Error.
+func WithInitialTags(tags ...string) Option {
+	return func(c *config) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// WithInitialData attaches a copy of data to the Error.
+func WithInitialData(data map[string]any) Option {
+	return func(c *config) {
+		if c.data == nil {
+			c.data = map[string]any{}
+		}
+		maps.Copy(c.data, data)
+	}
+}
+
+// WithStackPackagePrefix renders only the stack frames whose function or file starts
+// with one of the given prefixes, e.g. "github.com/acme/". It can be used several times,
+// a frame is kept if it matches any prefix. The full stack is still captured.
+func WithStackPackagePrefix(prefixes ...string) Option {
+	return func(c *config) {
+		c.render.packagePrefixes = append(c.render.packagePrefixes, prefixes...)
+	}
+}
+
+// WithWrapSites records the location of every later WithError or WithErrors call
+// on the Error, exposed by WrapSites and logged as "wrap_sites".
+func WithWrapSites() Option {
+	return func(c *config) {
+		c.recordWrapSites = true
+	}
+}
+
+// WithoutContextTags disables the automatic tagging and timeout classification
+// applied when wrapping context.Canceled or context.DeadlineExceeded.
+func WithoutContextTags() Option {
+	return func(c *config) {
+		c.noContextTags = true
+	}
+}
+
+// WithGoroutineID records the ID of the creating goroutine, exposed by GoroutineID
+// and logged as "goroutine". It is opt-in since reading the ID has a cost.
+func WithGoroutineID() Option {
+	return func(c *config) {
+		c.goroutineID = true
+	}
+}
+
+// WithTimestamp records the creation time of the Error, exposed by CreatedAt
+// and logged as "created_at".
+func WithTimestamp() Option {
+	return func(c *config) {
+		c.timestamp = true
+	}
+}
+
+// WithSlogSourceStack logs the stack of the Error as a list of *slog.Source,
+// the outermost frame first, so log processors treat each frame like a source
+// location, instead of as a single string.
+func WithSlogSourceStack() Option {
+	return func(c *config) {
+		c.render.slogSource = true
+	}
+}
+
+// WithStackTrimBottom renders the stack only down to the first frame whose function
+// contains substr, e.g. the HTTP router serving the request, cutting that frame and
+// every frame below it. Unlike WithStackPackagePrefix, it sets a cut point rather
+// than filtering frames. The full stack is still captured.
+func WithStackTrimBottom(substr string) Option {
+	return func(c *config) {
+		c.render.trimBottom = substr
+	}
+}
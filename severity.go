@@ -0,0 +1,87 @@
+package zerrors
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Severity classifies how serious an error is, see WithSeverity.
+// The zero value means no severity was set.
+type Severity int
+
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unset"
+	}
+}
+
+// Level returns the slog level errors of severity s are logged at by Log.
+// Critical errors and errors without a severity are logged at slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// WithSeverity sets the severity of the error.
+func (e *Error[T]) WithSeverity(s Severity) *Error[T] {
+	e.severity = s
+	return e
+}
+
+// Severity returns the severity of the error, which is unset by default.
+func (e *Error[T]) Severity() Severity {
+	return e.severity
+}
+
+// Log logs e with logger, see the Log function.
+func (e *Error[T]) Log(ctx context.Context, logger *slog.Logger) {
+	Log(ctx, logger, e)
+}
+
+// Log logs err with logger at the level of the first severity set in its chain,
+// with the structured view of LogValue under the "error" key.
+// A nil logger uses slog.Default, and a nil err, including a nil *Error, is not logged.
+func Log(ctx context.Context, logger *slog.Logger, err error) {
+	if orNil(err) == nil {
+		return
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	var severity Severity
+	for e := range Iter(err) {
+		if s, ok := e.(chainNode); ok && s.Severity() != 0 {
+			severity = s.Severity()
+			break
+		}
+	}
+	logger.LogAttrs(ctx, severity.Level(), err.Error(), slog.Any("error", err))
+}
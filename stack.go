@@ -1,71 +1,383 @@
 package zerrors
 
 import (
+	"bytes"
 	"fmt"
+	"log/slog"
+	"path"
 	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 const defaultStackDepth = 32
 
+// maxRenderedFrames caps the number of frames rendered by stack.String, 0 means unlimited.
+var maxRenderedFrames atomic.Int64
+
+// collapseSharedFrames collapses the frames an inner stack shares with the stack of its wrapper.
+var collapseSharedFrames atomic.Bool
+
+// SetCollapseSharedFrames controls whether the stack of a wrapped error, when logged
+// as part of its wrapper, omits the trailing frames it shares with the wrapper's stack,
+// rendering them as "... N more" instead. The stack of the outermost error is always
+// rendered in full. Disabled by default.
+func SetCollapseSharedFrames(collapse bool) {
+	collapseSharedFrames.Store(collapse)
+}
+
+// singleLineStack renders stacks on a single line, see SetSingleLineStack.
+var singleLineStack atomic.Bool
+
+// SetSingleLineStack controls whether stacks are rendered on a single line, with the
+// frames joined by " <- ", for sinks where newlines break the framing of log lines.
+// Disabled by default, each frame being rendered on its own line.
+func SetSingleLineStack(singleLine bool) {
+	singleLineStack.Store(singleLine)
+}
+
+// SetMaxRenderedFrames limits how many frames are rendered in stack output,
+// appending a "... (K more)" marker for the rest. The full stack is still captured.
+// A value of 0 or less renders every frame, which is the default.
+func SetMaxRenderedFrames(n int) {
+	maxRenderedFrames.Store(int64(max(n, 0)))
+}
+
+// renderConfig holds the per-error settings applied when rendering a stack.
+type renderConfig struct {
+	packagePrefixes []string
+	slogSource      bool
+	trimBottom      string
+}
+
+// keep reports whether the frame should be rendered.
+func (rc renderConfig) keep(f stackFrame) bool {
+	if len(rc.packagePrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range rc.packagePrefixes {
+		if strings.HasPrefix(f.fullFunction, prefix) || strings.HasPrefix(f.file, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cut returns the number of frames rendered before the first frame matching
+// the trimBottom substring, or len(frames) if it is unset or nothing matches.
+func (rc renderConfig) cut(frames []stackFrame) int {
+	if rc.trimBottom == "" {
+		return len(frames)
+	}
+	for i, f := range frames {
+		if strings.Contains(f.fullFunction, rc.trimBottom) {
+			return i
+		}
+	}
+	return len(frames)
+}
+
+// Frame is a single location in the source code.
+type Frame struct {
+	File     string
+	Line     int
+	Function string
+}
+
+func (f Frame) String() string {
+	if f.Function != "" {
+		return fmt.Sprintf("%s:%d %s()", f.File, f.Line, f.Function)
+	}
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// captureFrame returns the location of a caller, skipping the first 'skip' frames,
+// with 0 identifying the caller of captureFrame.
+func captureFrame(skip int) (Frame, bool) {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return Frame{}, false
+	}
+	var function string
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		function = fn.Name()
+	}
+	return Frame{File: trimFilePath(file, function), Line: line, Function: trimFuncName(function)}, true
+}
+
 type stackFrame struct {
-	pc       uintptr
-	file     string
-	function string
-	line     int
+	pc           uintptr
+	file         string
+	function     string
+	fullFunction string
+	line         int
 }
 
 func (f *stackFrame) String() string {
-	if f.function != "" {
-		return fmt.Sprintf("%s:%d %s()", f.file, f.line, f.function)
-	}
-	return fmt.Sprintf("%s:%d", f.file, f.line)
+	return f.frame().String()
 }
 
+func (f *stackFrame) frame() Frame {
+	return Frame{File: f.file, Line: f.line, Function: f.function}
+}
+
+// stack holds captured program counters, resolved into frames the
+// first time they are needed, as most errors are never rendered.
 type stack struct {
+	pcs    []uintptr
+	once   sync.Once
 	frames []stackFrame
 }
 
+// resolved returns the frames of the stack, resolving them on first use.
+func (s *stack) resolved() []stackFrame {
+	s.once.Do(func() {
+		s.frames = resolveFrames(s.pcs)
+	})
+	return s.frames
+}
+
 func (s *stack) String() string {
+	return s.render(nil, renderConfig{})
+}
+
+// render renders the stack frames kept by rc, omitting the trailing frames it shares with parent.
+func (s *stack) render(parent *stack, rc renderConfig) string {
+	shared := s.sharedSuffix(parent)
+	all := s.resolved()
+
+	own := all[:len(all)-shared]
+	if cut := rc.cut(all); cut < len(own) {
+		own, shared = own[:cut], 0
+	}
+
+	var unique []stackFrame
+	for _, frame := range own {
+		if rc.keep(frame) {
+			unique = append(unique, frame)
+		}
+	}
+
+	frames := unique
+	if limit := int(maxRenderedFrames.Load()); limit > 0 && len(frames) > limit {
+		frames = frames[:limit]
+	}
+
+	lines := make([]string, 0, len(frames)+2) //nolint:mnd // room for both markers
+	for _, frame := range frames {
+		lines = append(lines, "at "+frame.String())
+	}
+	if hidden := len(unique) - len(frames); hidden > 0 {
+		lines = append(lines, fmt.Sprintf("... (%d more)", hidden))
+	}
+	if shared > 0 {
+		lines = append(lines, fmt.Sprintf("... %d more", shared))
+	}
+
+	if singleLineStack.Load() {
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(line, "at ")
+		}
+		return strings.Join(lines, " <- ")
+	}
 	var sb strings.Builder
-	for _, frame := range s.frames {
-		sb.WriteString("\n    at ")
-		sb.WriteString(frame.String())
+	for _, line := range lines {
+		sb.WriteString("\n    ")
+		sb.WriteString(line)
 	}
 	return sb.String()
 }
 
-// Capture a new stacktrace, skipping the first 'skip' frames.
-func captureStack(skip int) *stack {
-	pcs := make([]uintptr, defaultStackDepth)
-	n := runtime.Callers(skip+1, pcs)
+// sources returns the stack frames kept by rc as slog.Source values,
+// capped like render but without collapsing shared frames.
+func (s *stack) sources(rc renderConfig) []*slog.Source {
+	var sources []*slog.Source
+	all := s.resolved()
+	for _, frame := range all[:rc.cut(all)] {
+		if !rc.keep(frame) {
+			continue
+		}
+		if limit := int(maxRenderedFrames.Load()); limit > 0 && len(sources) == limit {
+			break
+		}
+		sources = append(sources, &slog.Source{Function: frame.fullFunction, File: frame.file, Line: frame.line})
+	}
+	return sources
+}
+
+// sharedSuffix returns the number of trailing frames s has in common with parent.
+func (s *stack) sharedSuffix(parent *stack) int {
+	if parent == nil {
+		return 0
+	}
+	frames, parentFrames := s.resolved(), parent.resolved()
+	n := 0
+	for n < len(frames) && n < len(parentFrames) &&
+		frames[len(frames)-1-n] == parentFrames[len(parentFrames)-1-n] {
+		n++
+	}
+	return n
+}
+
+// currentGoroutineID returns the ID of the calling goroutine, parsed from the
+// "goroutine N [status]:" header of its stack trace, or 0 if it cannot be parsed.
+func currentGoroutineID() uint64 {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header, ok := bytes.CutPrefix(header, []byte("goroutine "))
+	if !ok {
+		return 0
+	}
+	if i := bytes.IndexByte(header, ' '); i != -1 {
+		header = header[:i]
+	}
+	id, err := strconv.ParseUint(string(header), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// pcsPool holds the scratch buffers runtime.Callers writes into, so that
+// each captured stack only allocates the program counters it keeps.
+var pcsPool = sync.Pool{
+	New: func() any {
+		pcs := make([]uintptr, defaultStackDepth)
+		return &pcs
+	},
+}
+
+// Capture a new stacktrace of at most 'depth' frames, skipping the first 'skip' frames,
+// with 0 identifying the caller of captureStack.
+func captureStack(skip, depth int) *stack {
+	buf, _ := pcsPool.Get().(*[]uintptr)
+	if len(*buf) < depth {
+		*buf = make([]uintptr, depth)
+	}
+	defer pcsPool.Put(buf)
+
+	//nolint:mnd // skip runtime.Callers and captureStack
+	n := runtime.Callers(skip+2, (*buf)[:depth])
 	if n == 0 {
 		return nil
 	}
 
-	frames := make([]stackFrame, 0, n)
-	iter := runtime.CallersFrames(pcs[:n])
+	// The pooled buffer is reused, so the stack keeps its own copy.
+	return &stack{pcs: slices.Clone((*buf)[:n])}
+}
+
+// resolveFrames turns program counters into frames, skipping runtime and testing frames.
+func resolveFrames(pcs []uintptr) []stackFrame {
+	frames := make([]stackFrame, 0, len(pcs))
+	iter := runtime.CallersFrames(pcs)
 
 	for {
 		frame, more := iter.Next()
+
+		// Skip runtime frames and testing frames
+		if !strings.Contains(frame.File, "runtime/") && !strings.HasPrefix(frame.Function, "testing.") {
+			frames = append(frames, stackFrame{
+				pc:           frame.PC,
+				file:         trimFilePath(frame.File, frame.Function),
+				function:     trimFuncName(frame.Function),
+				fullFunction: frame.Function,
+				line:         frame.Line,
+			})
+		}
+
 		if !more {
 			break
 		}
+	}
 
-		// Skip runtime frames and testing frames
-		if strings.Contains(frame.File, "runtime/") || strings.Contains(frame.File, "_test.go") {
-			continue
+	return frames
+}
+
+// trimPrefix is stripped from file paths, see SetTrimPrefix.
+var trimPrefix atomic.Pointer[string]
+
+// SetTrimPrefix sets a prefix, such as the checkout directory on the build machine,
+// stripped from the file paths of the frames resolved afterwards.
+// An empty prefix restores the default trimming.
+func SetTrimPrefix(prefix string) {
+	trimPrefix.Store(&prefix)
+}
+
+// buildPaths returns the path of the main module and the import path of the
+// main package, if known.
+var buildPaths = sync.OnceValues(func() (string, string) {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path, info.Path
+	}
+	return "", ""
+})
+
+// moduleRoot is the directory of the main module root, learned from the first
+// resolved frame of a package inside the main module, see moduleRelativePath.
+var moduleRoot atomic.Pointer[string]
+
+// trimFilePath shortens the path of a source file containing function.
+// In order, it strips the prefix set with SetTrimPrefix, renders files of the
+// main module relative to its root, and falls back to trimming the GOPATH.
+func trimFilePath(file, function string) string {
+	if prefix := trimPrefix.Load(); prefix != nil && *prefix != "" {
+		if trimmed, ok := strings.CutPrefix(file, *prefix); ok {
+			return trimmed
+		}
+	}
+	if rel, ok := moduleRelativePath(file, function); ok {
+		return rel
+	}
+	return trimGoPath(file)
+}
+
+// moduleRelativePath returns the path of file relative to the main module root.
+// File paths on disk carry no module information, so the root directory is derived
+// once from a frame of a package inside the main module, as the directory of its
+// file minus the directory of the package within the module. The root then applies
+// to every file, including those of package main, whose functions are named "main.X".
+func moduleRelativePath(file, function string) (string, bool) {
+	root := moduleRoot.Load()
+	if root == nil {
+		dir, ok := learnModuleRoot(file, function)
+		if !ok {
+			return "", false
 		}
+		moduleRoot.CompareAndSwap(nil, &dir)
+		root = moduleRoot.Load()
+	}
+	return strings.CutPrefix(file, *root+"/")
+}
 
-		frames = append(frames, stackFrame{
-			pc:       frame.PC,
-			file:     trimGoPath(frame.File),
-			function: trimFuncName(frame.Function),
-			line:     frame.Line,
-		})
+// learnModuleRoot derives the directory of the main module root from the file
+// of a function, reporting false if the function is not in the main module.
+func learnModuleRoot(file, function string) (string, bool) {
+	module, mainPkg := buildPaths()
+	if module == "" || function == "" {
+		return "", false
+	}
+	pkg := strings.TrimSuffix(packagePath(function), "_test")
+	if pkg == "main" {
+		pkg = mainPkg
 	}
+	rel, ok := strings.CutPrefix(pkg, module)
+	if !ok || (rel != "" && rel[0] != '/') {
+		return "", false
+	}
+	return strings.CutSuffix(path.Dir(file), rel)
+}
 
-	return &stack{frames: frames}
+// packagePath returns the import path of the package declaring function.
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/") + 1
+	if dot := strings.Index(function[slash:], "."); dot != -1 {
+		return function[:slash+dot]
+	}
+	return function
 }
 
 // Helper function to trim the GOPATH from file paths.
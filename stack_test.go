@@ -0,0 +1,31 @@
+package zerrors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Package main functions are named "main.X" whatever the module, so only
+// an internal test can simulate the frames of a binary built from a module.
+func Test_trimFilePathMain(t *testing.T) {
+	restore := buildPaths
+	buildPaths = func() (string, string) { return "github.com/acme/app", "github.com/acme/app/cmd/app" }
+	moduleRoot.Store(nil)
+	t.Cleanup(func() {
+		buildPaths = restore
+		moduleRoot.Store(nil)
+	})
+
+	// The root is learned from the first frame inside the module, package main included.
+	require.Equal(t, "cmd/app/main.go", trimFilePath("/home/dev/app/cmd/app/main.go", "main.main"))
+	require.Equal(t, "internal/foo/foo.go", trimFilePath("/home/dev/app/internal/foo/foo.go", "github.com/acme/app/internal/foo.Run"))
+	require.Equal(t, "main.go", trimFilePath("/home/dev/app/main.go", "main.run"))
+
+	moduleRoot.Store(nil)
+	require.Equal(t, "internal/foo/foo.go", trimFilePath("/home/dev/app/internal/foo/foo.go", "github.com/acme/app/internal/foo.Run"))
+	require.Equal(t, "cmd/app/main.go", trimFilePath("/home/dev/app/cmd/app/main.go", "main.main"))
+
+	// Files outside the module keep the GOPATH trimming.
+	require.Equal(t, "github.com/other/lib/lib.go", trimFilePath("/root/go/src/github.com/other/lib/lib.go", "github.com/other/lib.Do"))
+}
@@ -0,0 +1,89 @@
+package zerrors
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	textEscaper   = strings.NewReplacer("%", "%25", "|", "%7C", ",", "%2C", "=", "%3D")
+	textUnescaper = strings.NewReplacer("%25", "%", "%7C", "|", "%2C", ",", "%3D", "=")
+)
+
+// MarshalText implements encoding.TextMarshaler with a compact single-line form
+// "code|tag1,tag2|k1=v1,k2=v2", meant for headers or flat key-value stores.
+// Tags and keys are sorted and separators in them are percent-escaped.
+// The encoding is lossy: only data of basic kinds (strings, booleans and numbers)
+// is kept, sensitive values are redacted, and the message and wrapped error are dropped.
+func (e *Error[T]) MarshalText() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString(textEscaper.Replace(string(e.code)))
+
+	sb.WriteByte('|')
+	tags := e.GetTags()
+	slices.Sort(tags)
+	for i, tag := range tags {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(textEscaper.Replace(tag))
+	}
+
+	sb.WriteByte('|')
+	data := e.exportedData()
+	first := true
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		switch data[k].(type) {
+		case string, bool, int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64, float32, float64:
+		default:
+			continue
+		}
+		if !first {
+			sb.WriteByte(',')
+		}
+		first = false
+		sb.WriteString(textEscaper.Replace(k))
+		sb.WriteByte('=')
+		sb.WriteString(textEscaper.Replace(fmt.Sprint(data[k])))
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, decoding the form written
+// by MarshalText into e, data values being decoded as strings.
+// It replaces the code, tags and data of e, and works on a zero Error.
+func (e *Error[T]) UnmarshalText(text []byte) error {
+	fields := strings.Split(string(text), "|")
+	if len(fields) != 3 { //nolint:mnd // code, tags and data
+		return fmt.Errorf("zerrors: invalid text encoding %q", text)
+	}
+
+	data := map[string]any{}
+	if fields[2] != "" {
+		for _, pair := range strings.Split(fields[2], ",") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("zerrors: invalid text encoding %q", text)
+			}
+			data[textUnescaper.Replace(k)] = textUnescaper.Replace(v)
+		}
+	}
+
+	var tags []string
+	if fields[1] != "" {
+		for _, tag := range strings.Split(fields[1], ",") {
+			tags = append(tags, textUnescaper.Replace(tag))
+		}
+	}
+
+	e.code = T(textUnescaper.Replace(fields[0]))
+	e.tags = nil
+	e.addTags(tags...)
+	e.data = data
+	e.sensitive = nil
+	return nil
+}
@@ -0,0 +1,84 @@
+// Package zerrorsgrpc converts zerrors errors into gRPC statuses.
+//
+// It is a separate module, so that the core module does not depend on gRPC.
+// For the same reason Error does not implement the GRPCStatus() hook itself,
+// and the interceptors of this package perform the conversion instead.
+package zerrorsgrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DeluxeOwl/zerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type coder interface {
+	CodeString() string
+}
+
+// GRPCStatus converts err into a gRPC status. The outermost zerrors code in the chain
+// is looked up in mapping, falling back to codes.Unknown. The status carries the error
+// message and, as a structpb.Struct detail, the flattened data of the chain.
+// It returns nil, which gRPC treats as OK, for a nil error.
+func GRPCStatus(err error, mapping map[string]codes.Code) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	code := codes.Unknown
+	for e := range zerrors.Iter(err) {
+		if c, ok := e.(coder); ok {
+			if mapped, ok := mapping[c.CodeString()]; ok {
+				code = mapped
+			}
+			break
+		}
+	}
+
+	st := status.New(code, err.Error())
+
+	data := zerrors.FlattenData(err)
+	if len(data) == 0 {
+		return st
+	}
+	fields := make(map[string]*structpb.Value, len(data))
+	for k, v := range data {
+		value, convErr := structpb.NewValue(v)
+		if convErr != nil {
+			value = structpb.NewStringValue(fmt.Sprint(v))
+		}
+		fields[k] = value
+	}
+	if withDetails, detailsErr := st.WithDetails(&structpb.Struct{Fields: fields}); detailsErr == nil {
+		return withDetails
+	}
+	return st
+}
+
+// UnaryServerInterceptor converts the zerrors errors returned by handlers
+// into gRPC statuses using mapping. Other errors are returned unchanged.
+func UnaryServerInterceptor(mapping map[string]codes.Code) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, convert(err, mapping)
+	}
+}
+
+// StreamServerInterceptor converts the zerrors errors returned by stream handlers
+// into gRPC statuses using mapping. Other errors are returned unchanged.
+func StreamServerInterceptor(mapping map[string]codes.Code) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return convert(handler(srv, ss), mapping)
+	}
+}
+
+func convert(err error, mapping map[string]codes.Code) error {
+	if len(zerrors.Codes(err)) == 0 {
+		return err
+	}
+	return GRPCStatus(err, mapping).Err()
+}
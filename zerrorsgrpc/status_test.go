@@ -0,0 +1,67 @@
+package zerrorsgrpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DeluxeOwl/zerrors"
+	"github.com/DeluxeOwl/zerrors/zerrorsgrpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type domainErr string
+
+const (
+	domainErrNotFound domainErr = "not_found"
+	domainErrInternal domainErr = "internal"
+)
+
+var mapping = map[string]codes.Code{
+	string(domainErrNotFound): codes.NotFound,
+}
+
+func Test_GRPCStatus(t *testing.T) {
+	err := zerrors.New(domainErrNotFound).
+		With("user_id", 123).
+		WithSensitive("email", "jane@example.com").
+		WithError(zerrors.New(domainErrInternal).With("query", "SELECT 1"))
+
+	st, ok := status.FromError(zerrorsgrpc.GRPCStatus(err, mapping).Err())
+	require.True(t, ok)
+	require.Equal(t, codes.NotFound, st.Code())
+	require.Equal(t, "not_found: internal", st.Message())
+
+	require.Len(t, st.Details(), 1)
+	details, ok := st.Details()[0].(*structpb.Struct)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{
+		"user_id": float64(123),
+		"email":   "[REDACTED]",
+		"query":   "SELECT 1",
+	}, details.AsMap())
+
+	require.Equal(t, codes.Unknown, zerrorsgrpc.GRPCStatus(zerrors.New(domainErrInternal), mapping).Code())
+	require.Equal(t, codes.Unknown, zerrorsgrpc.GRPCStatus(errors.New("plain"), mapping).Code())
+	require.Nil(t, zerrorsgrpc.GRPCStatus(nil, mapping))
+}
+
+func Test_UnaryServerInterceptor(t *testing.T) {
+	interceptor := zerrorsgrpc.UnaryServerInterceptor(mapping)
+	call := func(err error) error {
+		_, got := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+			return nil, err
+		})
+		return got
+	}
+
+	require.Equal(t, codes.NotFound, status.Code(call(zerrors.New(domainErrNotFound))))
+
+	plain := errors.New("plain")
+	require.Equal(t, plain, call(plain))
+	require.NoError(t, call(nil))
+}
@@ -0,0 +1,77 @@
+// Package zerrorshttp translates zerrors codes into HTTP responses.
+package zerrorshttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/DeluxeOwl/zerrors"
+)
+
+// StatusMapper maps error codes to HTTP status codes.
+// Register the codes with Set before serving requests, a StatusMapper
+// is safe for concurrent use only once it is no longer modified.
+type StatusMapper[T ~string] struct {
+	statuses map[T]int
+}
+
+// NewStatusMapper creates an empty StatusMapper.
+func NewStatusMapper[T ~string]() *StatusMapper[T] {
+	return &StatusMapper[T]{
+		statuses: map[T]int{},
+	}
+}
+
+// Set maps code to the given HTTP status.
+func (m *StatusMapper[T]) Set(code T, status int) *StatusMapper[T] {
+	m.statuses[code] = status
+	return m
+}
+
+// Status returns the HTTP status of the first error in the chain of err whose code
+// is registered, or http.StatusInternalServerError if there is none.
+func (m *StatusMapper[T]) Status(err error) int {
+	if zerr, ok := m.match(err); ok {
+		return m.statuses[zerr.Code()]
+	}
+	return http.StatusInternalServerError
+}
+
+// WriteError writes the status of err along with a JSON body holding its code and message.
+// The code is the one of the matched error, or of the outermost Error[T] when none matched.
+// A nil err is written as a 500 with an empty message.
+func (m *StatusMapper[T]) WriteError(w http.ResponseWriter, err error) {
+	body := struct {
+		Code    string `json:"code,omitempty"`
+		Message string `json:"message"`
+	}{}
+	if err != nil {
+		body.Message = err.Error()
+	}
+
+	status := http.StatusInternalServerError
+	if zerr, ok := m.match(err); ok {
+		status = m.statuses[zerr.Code()]
+		body.Code = zerr.CodeString()
+	} else if zerr, ok := zerrors.AsError[T](err); ok {
+		body.Code = zerr.CodeString()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// match returns the first Error[T] in the chain of err whose code is registered.
+func (m *StatusMapper[T]) match(err error) (*zerrors.Error[T], bool) {
+	for e := range zerrors.Iter(err) {
+		zerr, ok := e.(*zerrors.Error[T])
+		if !ok {
+			continue
+		}
+		if _, ok := m.statuses[zerr.Code()]; ok {
+			return zerr, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,52 @@
+package zerrorshttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DeluxeOwl/zerrors"
+	"github.com/DeluxeOwl/zerrors/zerrorshttp"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StatusMapper(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound     domainErr = "not_found"
+		domainErrUnauthorized domainErr = "unauthorized"
+		domainErrInternal     domainErr = "internal"
+	)
+
+	mapper := zerrorshttp.NewStatusMapper[domainErr]().
+		Set(domainErrNotFound, http.StatusNotFound).
+		Set(domainErrUnauthorized, http.StatusUnauthorized)
+
+	require.Equal(t, http.StatusNotFound, mapper.Status(zerrors.New(domainErrNotFound)))
+	require.Equal(t, http.StatusInternalServerError, mapper.Status(errors.New("plain")))
+
+	// The outer code is not registered, so the inner one decides.
+	err := zerrors.New(domainErrInternal).WithError(zerrors.New(domainErrUnauthorized))
+	require.Equal(t, http.StatusUnauthorized, mapper.Status(err))
+
+	rec := httptest.NewRecorder()
+	mapper.WriteError(rec, err)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"code":"unauthorized","message":"internal: unauthorized"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	mapper.WriteError(rec, zerrors.New(domainErrInternal))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.JSONEq(t, `{"code":"internal","message":"internal"}`, rec.Body.String())
+
+	// A handler may pass along a nil error, or a nil *Error returned by Wrap.
+	for _, err := range []error{nil, zerrors.Wrap(nil, domainErrInternal)} {
+		rec = httptest.NewRecorder()
+		mapper.WriteError(rec, err)
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.JSONEq(t, `{"message":""}`, rec.Body.String())
+	}
+}
@@ -0,0 +1,66 @@
+// Package zerrorsotel records zerrors errors on OpenTelemetry spans.
+package zerrorsotel
+
+import (
+	"fmt"
+
+	"github.com/DeluxeOwl/zerrors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RecordOnSpan records err as an event on span, sets the span status to error and
+// adds the codes, tags and flattened data of the whole chain as attributes, to both
+// the event and the span. It does nothing if err is nil.
+func RecordOnSpan(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	attrs := Attributes(err)
+	span.RecordError(err, trace.WithAttributes(attrs...))
+	span.SetStatus(codes.Error, err.Error())
+	span.SetAttributes(attrs...)
+}
+
+// Attributes returns the attributes describing the chain of err:
+// "error.code" holds the outermost code, "error.codes" every code,
+// "error.tags" every tag, and each data key is exported as "error.data.<key>".
+func Attributes(err error) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+
+	if errCodes := zerrors.Codes(err); len(errCodes) > 0 {
+		attrs = append(attrs,
+			attribute.String("error.code", errCodes[0]),
+			attribute.StringSlice("error.codes", errCodes),
+		)
+	}
+	if tags := zerrors.AllTags(err); len(tags) > 0 {
+		attrs = append(attrs, attribute.StringSlice("error.tags", tags))
+	}
+	for k, v := range zerrors.FlattenData(err) {
+		attrs = append(attrs, dataAttribute("error.data."+k, v))
+	}
+
+	return attrs
+}
+
+func dataAttribute(key string, v any) attribute.KeyValue {
+	switch v := v.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,60 @@
+package zerrorsotel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DeluxeOwl/zerrors"
+	"github.com/DeluxeOwl/zerrors/zerrorsotel"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func Test_RecordOnSpan(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	err := zerrors.New(domainErrNotFound).
+		With("user_id", 123).
+		Tags("iam").
+		WithError(zerrors.New(dbErrZeroRows).With("query", "SELECT 1").Tags("database"))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "lookup")
+	zerrorsotel.RecordOnSpan(span, err)
+	zerrorsotel.RecordOnSpan(span, nil)
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Equal(t, "not_found: zero_rows", spans[0].Status().Description)
+
+	want := []attribute.KeyValue{
+		attribute.String("error.code", "not_found"),
+		attribute.StringSlice("error.codes", []string{"not_found", "zero_rows"}),
+		attribute.StringSlice("error.tags", []string{"database", "iam"}),
+		attribute.Int("error.data.user_id", 123),
+		attribute.String("error.data.query", "SELECT 1"),
+	}
+	require.ElementsMatch(t, want, spans[0].Attributes())
+
+	events := spans[0].Events()
+	require.Len(t, events, 1)
+	require.Equal(t, "exception", events[0].Name)
+	require.Subset(t, events[0].Attributes, want)
+}
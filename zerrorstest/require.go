@@ -0,0 +1,60 @@
+// Package zerrorstest provides test assertions for zerrors errors.
+//
+// Each helper fails the test immediately, like the require package of testify,
+// with a message describing what the chain of the error holds instead.
+package zerrorstest
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/DeluxeOwl/zerrors"
+)
+
+// getter is implemented by every Error, regardless of its code type.
+type getter interface {
+	Get(key string) (any, bool)
+}
+
+// RequireCode fails the test unless an Error in the chain of err has the given code.
+func RequireCode[T ~string](t testing.TB, err error, code T) {
+	t.Helper()
+	if !errors.Is(err, zerrors.NewMatcher(code)) {
+		t.Fatalf("expected error with code %q, got codes %q in: %v", code, zerrors.Codes(err), err)
+	}
+}
+
+// RequireData fails the test unless the chain of err carries key with a value
+// deeply equal to want. As in FlattenData, the outermost Error holding key wins.
+func RequireData(t testing.TB, err error, key string, want any) {
+	t.Helper()
+	for e := range zerrors.Iter(err) {
+		g, ok := e.(getter)
+		if !ok {
+			continue
+		}
+		got, ok := g.Get(key)
+		if !ok {
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected data %q to be %#v, got %#v in: %v", key, want, got, err)
+		}
+		return
+	}
+	t.Fatalf("expected data %q, not found in: %v", key, err)
+}
+
+// RequireTags fails the test unless the chain of err carries all the given tags.
+func RequireTags(t testing.TB, err error, tags ...string) {
+	t.Helper()
+	got := zerrors.AllTags(err)
+	for _, tag := range tags {
+		if !slices.Contains(got, tag) {
+			t.Fatalf("expected tags %q, got %q in: %v", tags, got, err)
+			return
+		}
+	}
+}
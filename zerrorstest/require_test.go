@@ -0,0 +1,74 @@
+package zerrorstest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DeluxeOwl/zerrors"
+	"github.com/DeluxeOwl/zerrors/zerrorstest"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTB records the failure instead of stopping the test.
+type fakeTB struct {
+	testing.TB
+	failure string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	if f.failure == "" {
+		f.failure = fmt.Sprintf(format, args...)
+	}
+}
+
+func Test_Require(t *testing.T) {
+	type domainErr string
+
+	const (
+		domainErrNotFound domainErr = "not_found"
+		domainErrInternal domainErr = "internal"
+	)
+
+	type dbErr string
+
+	const (
+		dbErrZeroRows dbErr = "zero_rows"
+	)
+
+	errDB := zerrors.New(dbErrZeroRows).With("query", "SELECT 1").Tags("database")
+	err := zerrors.New(domainErrNotFound).
+		With("user_id", 123).
+		Tags("iam").
+		WithError(fmt.Errorf("lookup: %w", errDB))
+
+	zerrorstest.RequireCode(t, err, domainErrNotFound)
+	zerrorstest.RequireCode(t, err, dbErrZeroRows)
+	zerrorstest.RequireData(t, err, "user_id", 123)
+	zerrorstest.RequireData(t, err, "query", "SELECT 1")
+	zerrorstest.RequireTags(t, err, "iam", "database")
+
+	fake := &fakeTB{}
+	zerrorstest.RequireCode(fake, err, domainErrInternal)
+	require.Equal(t,
+		`expected error with code "internal", got codes ["not_found" "zero_rows"] in: not_found: lookup: zero_rows`,
+		fake.failure)
+
+	fake = &fakeTB{}
+	zerrorstest.RequireData(fake, err, "user_id", "123")
+	require.Equal(t,
+		`expected data "user_id" to be "123", got 123 in: not_found: lookup: zero_rows`,
+		fake.failure)
+
+	fake = &fakeTB{}
+	zerrorstest.RequireData(fake, errors.New("plain"), "user_id", 123)
+	require.Equal(t, `expected data "user_id", not found in: plain`, fake.failure)
+
+	fake = &fakeTB{}
+	zerrorstest.RequireTags(fake, err, "iam", "http")
+	require.Equal(t,
+		`expected tags ["iam" "http"], got ["database" "iam"] in: not_found: lookup: zero_rows`,
+		fake.failure)
+}